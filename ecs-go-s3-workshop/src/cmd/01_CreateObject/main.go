@@ -17,11 +17,9 @@ package main
 
 import (
 	"fmt"
+	"ops"
 	"strings"
 	"utils"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/s3"
 )
 
 func main() {
@@ -35,17 +33,30 @@ func main() {
 	// Get bucket name from config
 	bucket := config.GetString("s3.demo_bucket_name")
 
-	// Read key and content
+	// Read key, content and content type
 	reader := utils.NewInputReader()
 	key := reader.GetInputStr("Enter the object key:")
 	content := reader.GetInputStr("Enter the object content:")
+	contentType := reader.GetInputStr("Enter the content type (empty to detect):")
+	if len(contentType) == 0 {
+		contentType = config.GetString("s3.content_type")
+	}
+	contentDisposition := reader.GetInputStr("Enter the content disposition, e.g. attachment; filename=\"a.txt\" (empty for s3.content_disposition):")
+	if len(contentDisposition) == 0 {
+		contentDisposition = config.GetString("s3.content_disposition")
+	}
+	cacheControl := reader.GetInputStr("Enter the cache control, e.g. max-age=3600 (empty for s3.cache_control):")
+	if len(cacheControl) == 0 {
+		cacheControl = config.GetString("s3.cache_control")
+	}
 
-	// Create Object
-	_, err = s3client.PutObject(
-		&s3.PutObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-			Body:   strings.NewReader(content),
+	// Create Object, sending an additional checksum if s3.checksum_algorithm is configured
+	_, err = ops.PutObject(s3client, bucket, key, strings.NewReader(content),
+		&ops.PutOptions{
+			ChecksumAlgorithm:  utils.ChecksumAlgorithm(config),
+			ContentType:        contentType,
+			ContentDisposition: contentDisposition,
+			CacheControl:       cacheControl,
 		})
 	utils.Check(err)
 
@@ -18,10 +18,10 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"ops"
 	"utils"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/s3"
 )
 
 func main() {
@@ -35,21 +35,35 @@ func main() {
 	// Get bucket name from config
 	bucket := config.GetString("s3.demo_bucket_name")
 
-	// Read key
+	// Read key and optional preconditions
 	reader := utils.NewInputReader()
 	key := reader.GetInputStr("Enter the object key:")
-
-	// Get Object
-	resp, err := s3client.GetObject(
-		&s3.GetObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-		})
+	cond, err := reader.GetInputConditions(true)
 	utils.Check(err)
 
-	// Read body in response
+	// Get Object into buffer
 	buf := new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
+	resp, err := ops.GetObject(s3client, bucket, key, buf, &ops.GetOptions{Conditions: cond})
+	switch {
+	case utils.IsPreconditionFailed(err):
+		fmt.Printf("object [%s/%s] doesn't match If-Match, it was changed since you read it\n", bucket, key)
+		return
+	case utils.IsNotModified(err):
+		fmt.Printf("object [%s/%s] not modified\n", bucket, key)
+		return
+	}
+	utils.Check(err)
+
+	// A multipart ETag isn't the MD5 of the content, check it assuming 08_CreateLargeObject's part size
+	etag := aws.StringValue(resp.ETag)
+	if parts, ok := utils.MultipartETagParts(etag); ok {
+		if int64(parts) != (int64(buf.Len())+utils.DefaultPartSize-1)/utils.DefaultPartSize {
+			fmt.Printf("object [%s/%s] has %d parts of unknown size, its ETag can't be verified\n", bucket, key, parts)
+		} else {
+			utils.Check(utils.VerifyMultipartETag(bytes.NewReader(buf.Bytes()), utils.DefaultPartSize, etag))
+			fmt.Printf("object [%s/%s] matches its multipart ETag [%s]\n", bucket, key, etag)
+		}
+	}
 
 	fmt.Printf("object [%s/%s] content: [%s]\n", bucket, key, buf.String())
 }
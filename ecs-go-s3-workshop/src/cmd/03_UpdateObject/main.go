@@ -17,11 +17,9 @@ package main
 
 import (
 	"fmt"
+	"ops"
 	"strings"
 	"utils"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/s3"
 )
 
 func main() {
@@ -39,14 +37,15 @@ func main() {
 	reader := utils.NewInputReader()
 	key := reader.GetInputStr("Enter the object key:")
 	content := reader.GetInputStr("Enter the object content:")
+	cond, err := reader.GetInputConditions(false)
+	utils.Check(err)
 
-	// Create Object
-	_, err = s3client.PutObject(
-		&s3.PutObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-			Body:   strings.NewReader(content),
-		})
+	// Update Object
+	_, err = ops.PutObject(s3client, bucket, key, strings.NewReader(content), &ops.PutOptions{Conditions: cond})
+	if utils.IsPreconditionFailed(err) {
+		fmt.Printf("object [%s/%s] not updated: it was changed by someone else or the precondition doesn't match\n", bucket, key)
+		return
+	}
 	utils.Check(err)
 
 	fmt.Printf("updated object [%s/%s] with new content: [%s]\n", bucket, key, content)
@@ -17,6 +17,7 @@ package main
 
 import (
 	"fmt"
+	"ops"
 	"utils"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -34,16 +35,51 @@ func main() {
 	// Get bucket name from config
 	bucket := config.GetString("s3.demo_bucket_name")
 
-	// Read key and content
+	// Read key and version
 	reader := utils.NewInputReader()
 	key := reader.GetInputStr("Enter the object key:")
+	versionID := reader.GetInputStr("Enter the version ID (empty for current version):")
 
-	// Create Object
-	_, err = s3client.DeleteObject(
-		&s3.DeleteObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-		})
-	utils.Check(err)
+	if utils.DryRun() {
+		fmt.Printf("dry run: would delete object [%s/%s] version [%s]\n", bucket, key, versionID)
+		return
+	}
+
+	// Require the key to be typed again so a stray Enter can't delete anything
+	if !reader.ConfirmAction("Type the object key again to confirm deletion:", key) {
+		return
+	}
+
+	// Delete Object
+	utils.Check(deleteObject(s3client, bucket, key, versionID))
+}
+
+// deleteObject deletes key, or only versionID of it, and reports what happened.
+// Missing versions and buckets are reported rather than returned as errors.
+func deleteObject(s3client utils.S3API, bucket, key, versionID string) error {
+	resp, err := ops.DeleteObject(s3client, bucket, key, versionID)
+	switch utils.AWSErrCode(err) {
+	case utils.ErrCodeNoSuchVersion:
+		fmt.Printf("version [%s] of object [%s/%s] not found\n", versionID, bucket, key)
+		return nil
+	case s3.ErrCodeNoSuchBucket:
+		fmt.Printf("bucket [%s] not found\n", bucket)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 	fmt.Printf("object [%s/%s] deleted\n", bucket, key)
+
+	// Without a version ID a versioned bucket only gets a new delete marker,
+	// with one the version (or delete marker) is removed permanently
+	switch {
+	case len(versionID) == 0 && aws.BoolValue(resp.DeleteMarker):
+		fmt.Printf("delete marker [%s] created\n", aws.StringValue(resp.VersionId))
+	case len(versionID) > 0 && aws.BoolValue(resp.DeleteMarker):
+		fmt.Printf("delete marker [%s] removed\n", versionID)
+	case len(versionID) > 0:
+		fmt.Printf("version [%s] permanently removed\n", versionID)
+	}
+	return nil
 }
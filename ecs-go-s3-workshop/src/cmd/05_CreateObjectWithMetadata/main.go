@@ -17,11 +17,9 @@ package main
 
 import (
 	"fmt"
+	"ops"
 	"strings"
 	"utils"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/s3"
 )
 
 func main() {
@@ -40,21 +38,13 @@ func main() {
 	key := reader.GetInputStr("Enter the object key:")
 	content := reader.GetInputStr("Enter the object content:")
 
-	// Put Object Parameters
-	params := &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   strings.NewReader(content),
-	}
-
 	// Read metadata key and value
 	metaKey := reader.GetInputStr("Enter the metadata key:")
 	metaValue := reader.GetInputStr("Enter the metadata content:")
-	// Can set one or more Metadatas
-	params.SetMetadata(map[string]*string{metaKey: aws.String(metaValue)})
 
-	// Create Object
-	_, err = s3client.PutObject(params)
+	// Create Object, can set one or more Metadatas
+	_, err = ops.PutObject(s3client, bucket, key, strings.NewReader(content),
+		&ops.PutOptions{Metadata: map[string]string{metaKey: metaValue}})
 	utils.Check(err)
 
 	fmt.Printf("created object [%s/%s] with metadata [%s=%s] and content: [%s]\n",
@@ -16,12 +16,10 @@ package main
  */
 
 import (
-	"bytes"
 	"fmt"
+	"io/ioutil"
+	"ops"
 	"utils"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/s3"
 )
 
 func main() {
@@ -38,18 +36,10 @@ func main() {
 
 	// Get bucket name from config
 	bucket := config.GetString("s3.demo_bucket_name")
-	// Get Object
-	resp, err := s3client.GetObject(
-		&s3.GetObjectInput{
-			Bucket: aws.String(bucket),
-			Key:    aws.String(key),
-		})
+	// Get Object, only its metadata is of interest here
+	resp, err := ops.GetObject(s3client, bucket, key, ioutil.Discard, nil)
 	utils.Check(err)
 
-	// Read body in response
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
-
 	fmt.Printf("Metadata for [%s/%s]\n", bucket, key)
 	for k, v := range resp.Metadata {
 		fmt.Printf("    %s = %s\n", k, *v)
@@ -60,7 +60,6 @@ func theHardWay() {
 	utils.Check(err)
 	uploadID := *initResp.UploadId
 
-	const PartSize = 5 << 20 // 5MB
 	var (
 		parts      []*s3.CompletedPart
 		partNumber int64
@@ -75,8 +74,8 @@ func theHardWay() {
 	for remainingLength := fStat.Size(); remainingLength > 0; {
 		partNumber++
 
-		if remainingLength > PartSize {
-			readLen = PartSize
+		if remainingLength > utils.DefaultPartSize {
+			readLen = utils.DefaultPartSize
 		} else {
 			readLen = remainingLength
 		}
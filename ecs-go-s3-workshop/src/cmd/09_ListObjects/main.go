@@ -114,25 +114,19 @@ func main() {
 		resp, err := s3client.ListObjects(listObjectInput)
 		utils.Check(err)
 
-		fmt.Printf("-----------------\n")
-		fmt.Printf("Bucket: %s\n", bucket)
-		fmt.Printf("Prefix: %s\n", *resp.Prefix)
-		fmt.Printf("Delimiter: %s\n", *resp.Delimiter)
-		fmt.Printf("Marker: %s\n", *resp.Marker)
-		fmt.Printf("IsTruncated? %t\n", *resp.IsTruncated)
-		if resp.NextMarker != nil {
-			fmt.Printf("NextMarker: %s\n", *resp.NextMarker)
-		}
-		fmt.Printf("\n")
-
-		for _, cPrefix := range resp.CommonPrefixes {
-			fmt.Printf("CommonPrefix: %s\n", *cPrefix.Prefix)
-		}
+		// The page above may be cut short by marker and maxKeys, the stats cover the whole prefix
+		stats, err := utils.ComputeBucketStats(s3client, bucket, prefix)
+		utils.Check(err)
 
-		fmt.Printf("%30s %10s %s\n", "LastModified", "Size", "Key")
-		fmt.Printf("------------------------------ ---------- ------------------------------------------\n")
-		for _, obj := range resp.Contents {
-			fmt.Printf("%30s %10d %s\n", *obj.LastModified, *obj.Size, *obj.Key)
+		if utils.IsJSONOutput() {
+			objects := make([]*utils.ObjectInfo, 0, len(resp.Contents))
+			for _, obj := range resp.Contents {
+				objects = append(objects, utils.NewObjectInfo(*obj.Key, *obj.Size, *obj.ETag, *obj.LastModified))
+			}
+			utils.Check(utils.PrintJSON(&utils.ObjectListing{Objects: objects, Stats: stats}))
+		} else {
+			printListing(bucket, resp)
+			fmt.Printf("\n[%s/%s]: %s\n", bucket, prefix, stats)
 		}
 
 		fmt.Println("Another? (Y/N) ")
@@ -145,13 +139,41 @@ func main() {
 
 	// Cleanup
 	fmt.Println()
-	fmt.Printf("Do clean up before exit...")
+	fmt.Println("Do clean up before exit...")
+	progress := utils.NewProgress("deleted objects")
+	progress.Start()
 	for _, key := range KEYLIST {
 		s3client.DeleteObject(
 			&s3.DeleteObjectInput{
 				Bucket: aws.String(bucket),
 				Key:    aws.String(key),
 			})
+		progress.Increment(1)
 	}
+	progress.Done()
 	fmt.Println("Done")
 }
+
+// printListing prints a ListObjects response as human-readable text
+func printListing(bucket string, resp *s3.ListObjectsOutput) {
+	fmt.Printf("-----------------\n")
+	fmt.Printf("Bucket: %s\n", bucket)
+	fmt.Printf("Prefix: %s\n", *resp.Prefix)
+	fmt.Printf("Delimiter: %s\n", *resp.Delimiter)
+	fmt.Printf("Marker: %s\n", *resp.Marker)
+	fmt.Printf("IsTruncated? %t\n", *resp.IsTruncated)
+	if resp.NextMarker != nil {
+		fmt.Printf("NextMarker: %s\n", *resp.NextMarker)
+	}
+	fmt.Printf("\n")
+
+	for _, cPrefix := range resp.CommonPrefixes {
+		fmt.Printf("CommonPrefix: %s\n", *cPrefix.Prefix)
+	}
+
+	fmt.Printf("%30s %10s %s\n", "LastModified", "Size", "Key")
+	fmt.Printf("------------------------------ ---------- ------------------------------------------\n")
+	for _, obj := range resp.Contents {
+		fmt.Printf("%30s %10d %s\n", *obj.LastModified, *obj.Size, *obj.Key)
+	}
+}
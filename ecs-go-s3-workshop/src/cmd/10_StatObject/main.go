@@ -0,0 +1,99 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"ops"
+	"sort"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read key
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+
+	// Head Object
+	resp, err := ops.StatObject(s3client, bucket, key)
+	// HeadObject has no response body, so a missing object only shows up as a 404
+	if utils.IsAWSErrCode(err, utils.ErrCodeNotFound) {
+		fmt.Printf("object [%s/%s] not found\n", bucket, key)
+		return
+	}
+	utils.Check(err)
+
+	// S3 omits the storage class header for STANDARD objects
+	storageClass := aws.StringValue(resp.StorageClass)
+	if storageClass == "" {
+		storageClass = s3.StorageClassStandard
+	}
+
+	if utils.IsJSONOutput() {
+		info := utils.NewObjectInfo(key, aws.Int64Value(resp.ContentLength), aws.StringValue(resp.ETag), aws.TimeValue(resp.LastModified))
+		info.ContentType = aws.StringValue(resp.ContentType)
+		info.StorageClass = storageClass
+		info.Metadata = aws.StringValueMap(resp.Metadata)
+		info.ContentDisposition = aws.StringValue(resp.ContentDisposition)
+		info.CacheControl = aws.StringValue(resp.CacheControl)
+		utils.Check(utils.PrintJSON(info))
+		return
+	}
+
+	// User metadata (x-amz-meta-*) is printed sorted by key
+	metaKeys := make([]string, 0, len(resp.Metadata))
+	for k := range resp.Metadata {
+		metaKeys = append(metaKeys, k)
+	}
+	sort.Strings(metaKeys)
+
+	// Align the values past the longest field name
+	width := len("ContentDisposition")
+	for _, k := range metaKeys {
+		if len("x-amz-meta-"+k) > width {
+			width = len("x-amz-meta-" + k)
+		}
+	}
+
+	fmt.Printf("Stat for [%s/%s]\n", bucket, key)
+	fmt.Printf("    %-*s %d\n", width, "ContentLength", aws.Int64Value(resp.ContentLength))
+	fmt.Printf("    %-*s %s\n", width, "ContentType", aws.StringValue(resp.ContentType))
+	fmt.Printf("    %-*s %s\n", width, "ETag", aws.StringValue(resp.ETag))
+	fmt.Printf("    %-*s %s\n", width, "LastModified", aws.TimeValue(resp.LastModified))
+	fmt.Printf("    %-*s %s\n", width, "StorageClass", storageClass)
+	if contentDisposition := aws.StringValue(resp.ContentDisposition); len(contentDisposition) > 0 {
+		fmt.Printf("    %-*s %s\n", width, "ContentDisposition", contentDisposition)
+	}
+	if cacheControl := aws.StringValue(resp.CacheControl); len(cacheControl) > 0 {
+		fmt.Printf("    %-*s %s\n", width, "CacheControl", cacheControl)
+	}
+	for _, k := range metaKeys {
+		fmt.Printf("    %-*s %s\n", width, "x-amz-meta-"+k, aws.StringValue(resp.Metadata[k]))
+	}
+}
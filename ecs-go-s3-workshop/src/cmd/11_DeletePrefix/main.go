@@ -0,0 +1,111 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"ops"
+	"os"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read prefix, an empty prefix would match the whole bucket
+	reader := utils.NewInputReader()
+	prefix := reader.GetInputStr("Enter the prefix to delete:")
+	if len(prefix) == 0 {
+		fmt.Println("prefix must not be empty")
+		return
+	}
+
+	// Ctrl-C stops listing after the page in flight
+	ctx, stop := utils.SetupSignalContext()
+
+	// List all keys under prefix page by page
+	var objIdentifierSlice []*s3.ObjectIdentifier
+	progress := utils.NewProgress("listed objects")
+	progress.Start()
+	err = ops.ListObjectsWithContext(ctx, s3client, bucket, prefix, func(obj *s3.Object) {
+		objIdentifierSlice = append(objIdentifierSlice, &s3.ObjectIdentifier{Key: obj.Key})
+		progress.Increment(1)
+	})
+	progress.Done()
+	if ctx.Err() != nil {
+		fmt.Printf("interrupted after listing %d objects under [%s/%s], nothing deleted\n", len(objIdentifierSlice), bucket, prefix)
+		os.Exit(utils.ExitInterrupted)
+	}
+	stop()
+	utils.Check(err)
+
+	total := len(objIdentifierSlice)
+	if total == 0 {
+		fmt.Printf("no objects found under [%s/%s]\n", bucket, prefix)
+		return
+	}
+
+	if utils.DryRun() {
+		for _, obj := range objIdentifierSlice {
+			fmt.Printf("dry run: would delete [%s/%s]\n", bucket, *obj.Key)
+		}
+		fmt.Printf("dry run: would delete %d objects under [%s/%s]\n", total, bucket, prefix)
+		return
+	}
+
+	// Require the prefix to be typed again before deleting anything
+	fmt.Printf("found %d objects under [%s/%s]\n", total, bucket, prefix)
+	if !reader.ConfirmAction("Type the prefix again to confirm deletion:", prefix) {
+		return
+	}
+
+	// Delete Objects in batches, Ctrl-C stops after the batch in flight
+	ctx, stop = utils.SetupSignalContext()
+	defer stop()
+	deleted := 0
+	failed, err := ops.DeleteObjects(s3client, bucket, objIdentifierSlice,
+		&ops.DeleteOptions{
+			Attempts: config.GetInt("s3.delete_attempts"),
+			Context:  ctx,
+			Progress: func(n int) {
+				deleted = n
+				fmt.Printf("deleted %d/%d objects\n", deleted, total)
+			},
+		})
+	if ctx.Err() == nil {
+		utils.Check(err)
+	}
+
+	for _, e := range failed {
+		fmt.Printf("failed to delete [%s]: %s\n", aws.StringValue(e.Key), aws.StringValue(e.Message))
+	}
+	fmt.Printf("deleted %d objects under [%s/%s], %d failed\n", deleted, bucket, prefix, len(failed))
+	if ctx.Err() != nil {
+		fmt.Printf("interrupted: %d objects not deleted\n", total-deleted-len(failed))
+		os.Exit(utils.ExitInterrupted)
+	}
+}
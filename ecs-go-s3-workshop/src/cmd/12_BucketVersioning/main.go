@@ -0,0 +1,89 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Get versioning status, Status is nil if versioning was never enabled
+	resp, err := s3client.GetBucketVersioning(&s3.GetBucketVersioningInput{Bucket: aws.String(bucket)})
+	utils.Check(err)
+	status := aws.StringValue(resp.Status)
+	if status == "" {
+		status = "Unversioned"
+	}
+	fmt.Printf("bucket [%s] versioning status: [%s]\n", bucket, status)
+
+	// Read the new status
+	reader := utils.NewInputReader()
+	answer := reader.GetInputStr("Enable or suspend versioning? (E/S, empty to keep current):")
+	switch strings.ToUpper(answer) {
+	case "E":
+		status = s3.BucketVersioningStatusEnabled
+	case "S":
+		status = s3.BucketVersioningStatusSuspended
+	default:
+		status = ""
+	}
+
+	if len(status) > 0 {
+		// Put Bucket Versioning
+		_, err = s3client.PutBucketVersioning(
+			&s3.PutBucketVersioningInput{
+				Bucket: aws.String(bucket),
+				VersioningConfiguration: &s3.VersioningConfiguration{
+					Status: aws.String(status),
+				},
+			})
+		utils.Check(err)
+		fmt.Printf("bucket [%s] versioning status set to [%s]\n", bucket, status)
+	}
+
+	// Check versioning status again
+	resp, err = s3client.GetBucketVersioning(&s3.GetBucketVersioningInput{Bucket: aws.String(bucket)})
+	utils.Check(err)
+	if aws.StringValue(resp.Status) != s3.BucketVersioningStatusEnabled {
+		return
+	}
+
+	// List Object Versions
+	lovResp, err := s3client.ListObjectVersions(&s3.ListObjectVersionsInput{Bucket: aws.String(bucket)})
+	utils.Check(err)
+
+	fmt.Println()
+	fmt.Printf("%-40s %-8s %s\n", "VersionId", "IsLatest", "Key")
+	fmt.Printf("---------------------------------------- -------- ------------------------------------------\n")
+	for _, ver := range lovResp.Versions {
+		fmt.Printf("%-40s %-8t %s\n", aws.StringValue(ver.VersionId), aws.BoolValue(ver.IsLatest), aws.StringValue(ver.Key))
+	}
+}
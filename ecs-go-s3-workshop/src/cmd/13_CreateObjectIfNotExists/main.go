@@ -0,0 +1,64 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read key and content
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+	content := reader.GetInputStr("Enter the object content:")
+
+	// Put Object Parameters
+	params := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(content),
+	}
+
+	// If-None-Match: * makes the server reject the PUT if any object exists under
+	// key, so concurrent creators can't overwrite each other. PutObjectInput has
+	// no field for it, the request option sets the header.
+	cond := &utils.Conditions{IfNoneMatch: "*"}
+
+	// Create Object
+	_, err = s3client.PutObjectWithContext(aws.BackgroundContext(), params, cond.PutOption())
+	if utils.IsPreconditionFailed(err) {
+		fmt.Printf("object [%s/%s] already exists, not overwritten\n", bucket, key)
+		return
+	}
+	utils.Check(err)
+
+	fmt.Printf("created object [%s/%s] with content: [%s]\n", bucket, key, content)
+}
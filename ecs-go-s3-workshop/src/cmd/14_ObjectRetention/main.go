@@ -0,0 +1,105 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DateLayout is the expected format of the retain-until date
+const DateLayout = "2006-01-02"
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read key
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+
+	// Get Object Retention
+	var current *s3.ObjectLockRetention
+	resp, err := s3client.GetObjectRetention(
+		&s3.GetObjectRetentionInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	if utils.IsAWSErrCode(err, utils.ErrCodeNoSuchObjectLockConfiguration) {
+		fmt.Printf("object [%s/%s] has no retention\n", bucket, key)
+	} else {
+		utils.Check(err)
+		current = resp.Retention
+		fmt.Printf("object [%s/%s] retention: mode [%s] until [%s]\n",
+			bucket, key, aws.StringValue(current.Mode), aws.TimeValue(current.RetainUntilDate).Format(DateLayout))
+	}
+
+	// Read mode and retain-until date
+	mode := strings.ToUpper(reader.GetInputStr("Enter the retention mode (GOVERNANCE/COMPLIANCE, empty to keep current):"))
+	if len(mode) == 0 {
+		return
+	}
+	if mode != s3.ObjectLockRetentionModeGovernance && mode != s3.ObjectLockRetentionModeCompliance {
+		fmt.Printf("invalid retention mode [%s]\n", mode)
+		return
+	}
+	untilStr := reader.GetInputStr("Enter the retain-until date (YYYY-MM-DD):")
+	until, err := time.Parse(DateLayout, untilStr)
+	utils.Check(err)
+	if !until.After(time.Now()) {
+		fmt.Printf("retain-until date [%s] must be in the future\n", untilStr)
+		return
+	}
+
+	// COMPLIANCE retention can't be weakened by anyone until it expires, S3
+	// rejects both switching to GOVERNANCE and shortening the period
+	if current != nil && aws.StringValue(current.Mode) == s3.ObjectLockRetentionModeCompliance {
+		if mode != s3.ObjectLockRetentionModeCompliance {
+			fmt.Println("object is locked in COMPLIANCE mode, its mode can't be changed until the retention expires")
+			return
+		}
+		if until.Before(aws.TimeValue(current.RetainUntilDate)) {
+			fmt.Println("object is locked in COMPLIANCE mode, its retain-until date can only be extended")
+			return
+		}
+	}
+
+	// Put Object Retention
+	_, err = s3client.PutObjectRetention(
+		&s3.PutObjectRetentionInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Retention: &s3.ObjectLockRetention{
+				Mode:            aws.String(mode),
+				RetainUntilDate: aws.Time(until),
+			},
+		})
+	utils.Check(err)
+
+	fmt.Printf("object [%s/%s] retention set: mode [%s] until [%s]\n", bucket, key, mode, untilStr)
+}
@@ -0,0 +1,127 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Print current lifecycle rules
+	rules := getRules(s3client, bucket)
+	printRules(bucket, rules)
+
+	reader := utils.NewInputReader()
+	answer := reader.GetInputStr("Apply a new expiration rule? (Y/N)")
+	if strings.ToUpper(answer) != "Y" {
+		return
+	}
+
+	// Read rule prefix and expiration days
+	prefix := reader.GetInputStr("Enter the prefix the rule applies to (empty for whole bucket):")
+	days, err := reader.GetInputInt("Enter the number of days after which objects expire:")
+	utils.Check(err)
+
+	// Put Bucket Lifecycle Configuration replaces all rules, so the new rule is merged
+	// into the current ones, replacing the rule of the same ID if there's one
+	rules = mergeRule(rules, &s3.LifecycleRule{
+		ID:         aws.String(fmt.Sprintf("expire-%s-after-%d-days", prefix, days)),
+		Status:     aws.String(s3.ExpirationStatusEnabled),
+		Filter:     &s3.LifecycleRuleFilter{Prefix: aws.String(prefix)},
+		Expiration: &s3.LifecycleExpiration{Days: aws.Int64(days)},
+	})
+	_, err = s3client.PutBucketLifecycleConfiguration(
+		&s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 aws.String(bucket),
+			LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+		})
+	utils.Check(err)
+	fmt.Printf("applied lifecycle rule to bucket [%s]\n", bucket)
+
+	// Print resulting lifecycle rules
+	printRules(bucket, getRules(s3client, bucket))
+}
+
+// getRules returns the lifecycle rules of bucket, none if it has no lifecycle configuration
+func getRules(s3client *s3.S3, bucket string) []*s3.LifecycleRule {
+	resp, err := s3client.GetBucketLifecycleConfiguration(
+		&s3.GetBucketLifecycleConfigurationInput{
+			Bucket: aws.String(bucket),
+		})
+	if utils.IsAWSErrCode(err, utils.ErrCodeNoSuchLifecycleConfiguration) {
+		return nil
+	}
+	utils.Check(err)
+	return resp.Rules
+}
+
+// mergeRule returns rules with rule replacing the one of the same ID, or added
+// if there's none. Older rules carrying the prefix at the top level are moved to
+// Filter, as a configuration can't mix both.
+func mergeRule(rules []*s3.LifecycleRule, rule *s3.LifecycleRule) []*s3.LifecycleRule {
+	merged := make([]*s3.LifecycleRule, 0, len(rules)+1)
+	for _, r := range rules {
+		if aws.StringValue(r.ID) == aws.StringValue(rule.ID) {
+			fmt.Printf("replacing lifecycle rule %s\n", aws.StringValue(r.ID))
+			continue
+		}
+		if r.Filter == nil {
+			r.Filter = &s3.LifecycleRuleFilter{Prefix: aws.String(aws.StringValue(r.Prefix))}
+			r.Prefix = nil
+		}
+		merged = append(merged, r)
+	}
+	return append(merged, rule)
+}
+
+// printRules prints lifecycle rules of bucket
+func printRules(bucket string, rules []*s3.LifecycleRule) {
+	if len(rules) == 0 {
+		fmt.Printf("bucket [%s] has no lifecycle rules\n", bucket)
+		return
+	}
+
+	fmt.Printf("Lifecycle rules for bucket [%s]\n", bucket)
+	for _, rule := range rules {
+		// Older rules carry the prefix at the top level instead of in Filter
+		prefix := aws.StringValue(rule.Prefix)
+		if rule.Filter != nil && rule.Filter.Prefix != nil {
+			prefix = *rule.Filter.Prefix
+		}
+		fmt.Printf("    %s [%s] prefix [%s]\n", aws.StringValue(rule.ID), aws.StringValue(rule.Status), prefix)
+		if rule.Expiration != nil && rule.Expiration.Days != nil {
+			fmt.Printf("        expire after %d days\n", *rule.Expiration.Days)
+		}
+		for _, t := range rule.Transitions {
+			fmt.Printf("        transition to %s after %d days\n", aws.StringValue(t.StorageClass), aws.Int64Value(t.Days))
+		}
+	}
+}
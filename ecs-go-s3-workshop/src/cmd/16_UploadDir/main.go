@@ -0,0 +1,189 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"context"
+	"fmt"
+	"ops"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DefaultWorkers is used when s3.upload_workers isn't set
+const DefaultWorkers = 4
+
+// MaxAttempts is how often a file is tried when the server keeps answering SlowDown
+const MaxAttempts = 5
+
+// uploadJob is a local file to upload as key
+type uploadJob struct {
+	path string
+	key  string
+}
+
+// uploadResult is the outcome of an uploadJob
+type uploadResult struct {
+	job uploadJob
+	// uploaded is false for files skipped as unchanged
+	uploaded bool
+	err      error
+}
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name, upload options and worker pool bounds from config
+	bucket := config.GetString("s3.demo_bucket_name")
+	opts := &ops.PutOptions{
+		ChecksumAlgorithm:  utils.ChecksumAlgorithm(config),
+		ContentType:        config.GetString("s3.content_type"),
+		ContentDisposition: config.GetString("s3.content_disposition"),
+		CacheControl:       config.GetString("s3.cache_control"),
+	}
+	workers := config.GetInt("s3.upload_workers")
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	minWorkers := config.GetInt("s3.upload_min_workers")
+	maxWorkers := config.GetInt("s3.upload_max_workers")
+	if maxWorkers <= 0 {
+		maxWorkers = workers
+	}
+	throttle := utils.NewThrottle(workers, minWorkers, maxWorkers)
+
+	// Read directory and key prefix
+	reader := utils.NewInputReader()
+	dir := reader.GetInputStr("Enter the directory path:")
+	prefix := reader.GetInputStr("Enter the key prefix (empty for none):")
+
+	// Collect files, keeping their path relative to dir as key
+	var jobs []uploadJob
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, uploadJob{path: p, key: path.Join(prefix, filepath.ToSlash(rel))})
+		return nil
+	})
+	utils.Check(err)
+
+	// Ctrl-C stops feeding new files, uploads in flight still complete
+	ctx, stop := utils.SetupSignalContext()
+	defer stop()
+
+	// Workers count the files they processed, the progress shows the running total
+	processed := &utils.Counter{}
+	progress := utils.NewProgressWithCounter("uploaded files", processed)
+	progress.Start()
+
+	// Start enough workers for maxWorkers, the throttle decides how many upload at once
+	jobCh := make(chan uploadJob)
+	resultCh := make(chan uploadResult)
+	var wg sync.WaitGroup
+	for i := 0; i < maxWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				uploaded, err := uploadThrottled(ctx, throttle, s3client, bucket, opts, job)
+				progress.Increment(1)
+				resultCh <- uploadResult{job: job, uploaded: uploaded, err: err}
+			}
+		}()
+	}
+
+	// Feed jobs until interrupted, then close results once all workers finished
+	go func() {
+	feed:
+		for _, job := range jobs {
+			select {
+			case jobCh <- job:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(jobCh)
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	// Collect failures and count the files skipped as unchanged
+	var failed []uploadResult
+	skipped := 0
+	for result := range resultCh {
+		switch {
+		case result.err != nil:
+			failed = append(failed, result)
+		case !result.uploaded:
+			skipped++
+		}
+	}
+	progress.Done()
+	done := int(processed.Snapshot())
+
+	for _, result := range failed {
+		fmt.Printf("failed to upload [%s] as [%s/%s]: %v\n", result.job.path, bucket, result.job.key, result.err)
+	}
+	fmt.Printf("uploaded [%s] to [%s/%s] with %d workers: %d uploaded, %d unchanged skipped, %d failed\n",
+		dir, bucket, prefix, throttle.Limit(), done-skipped-len(failed), skipped, len(failed))
+	if ctx.Err() != nil {
+		fmt.Printf("interrupted: %d of %d files not uploaded\n", len(jobs)-done, len(jobs))
+		os.Exit(utils.ExitInterrupted)
+	}
+}
+
+// uploadThrottled uploads job within the throttle, trying again after SlowDown unless ctx is done
+func uploadThrottled(ctx context.Context, throttle *utils.Throttle, s3client *s3.S3, bucket string, opts *ops.PutOptions, job uploadJob) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		throttle.Acquire()
+		uploaded, err := upload(s3client, bucket, opts, job)
+		throttle.Release(err)
+		if !utils.IsSlowDown(err) || attempt == MaxAttempts || ctx.Err() != nil {
+			return uploaded, err
+		}
+	}
+}
+
+// upload puts a single local file unless the object already has its content,
+// with -force it's uploaded regardless. It reports whether the file was uploaded.
+func upload(s3client *s3.S3, bucket string, opts *ops.PutOptions, job uploadJob) (bool, error) {
+	file, err := os.Open(job.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if utils.Force() {
+		_, err = ops.PutObject(s3client, bucket, job.key, file, opts)
+		return err == nil, err
+	}
+	return ops.PutObjectIfChanged(s3client, bucket, job.key, file, opts)
+}
@@ -0,0 +1,85 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Print current policy
+	printPolicy(s3client, bucket)
+
+	// Read policy file path
+	reader := utils.NewInputReader()
+	path := reader.GetInputStr("Enter the policy JSON file path (empty to keep current):")
+	if len(path) == 0 {
+		return
+	}
+
+	policy, err := ioutil.ReadFile(path)
+	utils.Check(err)
+
+	// Validate the policy is JSON before sending it
+	var doc interface{}
+	if err := json.Unmarshal(policy, &doc); err != nil {
+		fmt.Printf("file [%s] isn't valid JSON: %v\n", path, err)
+		return
+	}
+
+	// Put Bucket Policy
+	_, err = s3client.PutBucketPolicy(
+		&s3.PutBucketPolicyInput{
+			Bucket: aws.String(bucket),
+			Policy: aws.String(string(policy)),
+		})
+	utils.Check(err)
+	fmt.Printf("applied policy from [%s] to bucket [%s]\n", path, bucket)
+
+	// Print resulting policy
+	printPolicy(s3client, bucket)
+}
+
+// printPolicy pretty-prints the policy of bucket
+func printPolicy(s3client *s3.S3, bucket string) {
+	resp, err := s3client.GetBucketPolicy(&s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+	if utils.IsAWSErrCode(err, utils.ErrCodeNoSuchBucketPolicy) {
+		fmt.Printf("bucket [%s] policy: none\n", bucket)
+		return
+	}
+	utils.Check(err)
+
+	var buf bytes.Buffer
+	utils.Check(json.Indent(&buf, []byte(aws.StringValue(resp.Policy)), "", "  "))
+	fmt.Printf("bucket [%s] policy:\n%s\n", bucket, buf.String())
+}
@@ -0,0 +1,83 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read key, days and tier
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+	days, err := reader.GetInputInt("How many days should the restored copy be kept?")
+	utils.Check(err)
+	tier := reader.GetInputStr("Enter the restore tier (Standard/Bulk/Expedited, empty for Standard):")
+	if len(tier) == 0 {
+		tier = s3.TierStandard
+	}
+
+	// Restore Object
+	_, err = s3client.RestoreObject(
+		&s3.RestoreObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			RestoreRequest: &s3.RestoreRequest{
+				Days:                 aws.Int64(days),
+				GlacierJobParameters: &s3.GlacierJobParameters{Tier: aws.String(tier)},
+			},
+		})
+	if utils.IsAWSErrCode(err, utils.ErrCodeRestoreAlreadyInProgress) {
+		fmt.Printf("restore of object [%s/%s] is already in progress\n", bucket, key)
+	} else {
+		utils.Check(err)
+		fmt.Printf("requested restore of object [%s/%s] for %d days with tier [%s]\n", bucket, key, days, tier)
+	}
+
+	// Head Object to read the x-amz-restore header
+	resp, err := s3client.HeadObject(
+		&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	utils.Check(err)
+
+	// x-amz-restore looks like: ongoing-request="false", expiry-date="Fri, 23 Dec 2016 00:00:00 GMT"
+	restore := aws.StringValue(resp.Restore)
+	switch {
+	case len(restore) == 0:
+		fmt.Printf("object [%s/%s] has no restore status\n", bucket, key)
+	case strings.Contains(restore, `ongoing-request="true"`):
+		fmt.Printf("object [%s/%s] restore is ongoing\n", bucket, key)
+	default:
+		fmt.Printf("object [%s/%s] restore is complete: %s\n", bucket, key, restore)
+	}
+}
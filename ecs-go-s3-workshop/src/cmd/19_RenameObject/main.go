@@ -0,0 +1,64 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"ops"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read source and destination keys
+	reader := utils.NewInputReader()
+	srcKey := reader.GetInputStr("Enter the object key to rename:")
+	dstKey := reader.GetInputStr("Enter the new object key:")
+
+	if srcKey == dstKey {
+		fmt.Printf("object [%s/%s] already has that key, nothing to do\n", bucket, srcKey)
+		return
+	}
+
+	// S3 has no rename, copy to the new key first
+	resp, err := ops.CopyObject(s3client, bucket, srcKey, dstKey)
+	utils.Check(err)
+
+	// Only delete the source once the copy is confirmed to be in place
+	head, err := ops.StatObject(s3client, bucket, dstKey)
+	utils.Check(err)
+	if aws.StringValue(head.ETag) != aws.StringValue(resp.CopyObjectResult.ETag) {
+		fmt.Printf("copy [%s/%s] doesn't match the copied ETag, leaving [%s/%s] in place\n", bucket, dstKey, bucket, srcKey)
+		return
+	}
+
+	// Delete the source
+	_, err = ops.DeleteObject(s3client, bucket, srcKey, "")
+	utils.Check(err)
+
+	fmt.Printf("renamed object [%s/%s] to [%s/%s]\n", bucket, srcKey, bucket, dstKey)
+}
@@ -0,0 +1,124 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// List in-progress multipart uploads
+	uploads, err := listUploads(s3client, bucket)
+	utils.Check(err)
+	if len(uploads) == 0 {
+		fmt.Printf("no multipart uploads in progress in bucket [%s]\n", bucket)
+		return
+	}
+	fmt.Printf("%-25s %-40s %s\n", "INITIATED", "UPLOAD ID", "KEY")
+	for _, upload := range uploads {
+		fmt.Printf("%-25s %-40s %s\n", aws.TimeValue(upload.Initiated).Format(time.RFC3339),
+			aws.StringValue(upload.UploadId), aws.StringValue(upload.Key))
+	}
+
+	// Select uploads to abort
+	reader := utils.NewInputReader()
+	choice := reader.GetInputStr("Enter an upload ID to abort, O to abort all uploads older than a duration, or empty to quit:")
+	var selected []*s3.MultipartUpload
+	switch strings.ToUpper(choice) {
+	case "":
+		return
+	case "O":
+		age, err := reader.GetInputDuration("Abort uploads initiated more than how long ago? (e.g. 36h or 7d)")
+		utils.Check(err)
+		cutoff := time.Now().Add(-age)
+		for _, upload := range uploads {
+			if aws.TimeValue(upload.Initiated).Before(cutoff) {
+				selected = append(selected, upload)
+			}
+		}
+	default:
+		for _, upload := range uploads {
+			if aws.StringValue(upload.UploadId) == choice {
+				selected = append(selected, upload)
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Printf("upload [%s] not found in bucket [%s]\n", choice, bucket)
+			return
+		}
+	}
+
+	if utils.DryRun() {
+		for _, upload := range selected {
+			fmt.Printf("dry run: would abort upload [%s] of object [%s/%s]\n",
+				aws.StringValue(upload.UploadId), bucket, aws.StringValue(upload.Key))
+		}
+		return
+	}
+
+	// Abort Multipart Uploads, their parts are discarded
+	aborted := 0
+	for _, upload := range selected {
+		_, err := s3client.AbortMultipartUpload(
+			&s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(bucket),
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+		if utils.IsAWSErrCode(err, s3.ErrCodeNoSuchUpload) {
+			fmt.Printf("upload [%s] already completed or aborted\n", aws.StringValue(upload.UploadId))
+			continue
+		}
+		utils.Check(err)
+		fmt.Printf("aborted upload [%s] of object [%s/%s]\n", aws.StringValue(upload.UploadId), bucket, aws.StringValue(upload.Key))
+		aborted++
+	}
+	fmt.Printf("aborted %d of %d selected multipart uploads in bucket [%s]\n", aborted, len(selected), bucket)
+}
+
+// listUploads pages through all in-progress multipart uploads of bucket
+func listUploads(s3client *s3.S3, bucket string) ([]*s3.MultipartUpload, error) {
+	var uploads []*s3.MultipartUpload
+	params := &s3.ListMultipartUploadsInput{Bucket: aws.String(bucket)}
+	for {
+		resp, err := s3client.ListMultipartUploads(params)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, resp.Uploads...)
+		if !aws.BoolValue(resp.IsTruncated) {
+			return uploads, nil
+		}
+		params.KeyMarker = resp.NextKeyMarker
+		params.UploadIdMarker = resp.NextUploadIdMarker
+	}
+}
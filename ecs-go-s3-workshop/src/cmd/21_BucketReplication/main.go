@@ -0,0 +1,109 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"regexp"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+var (
+	// bucketARN matches a destination bucket such as arn:aws:s3:::replica-bucket
+	bucketARN = regexp.MustCompile(`^arn:aws:s3:::[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	// roleARN matches a role such as arn:aws:iam::123456789012:role/replication
+	roleARN = regexp.MustCompile(`^arn:aws:iam::[^:]*:role/[\w+=,.@/-]+$`)
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Print current replication rules
+	printReplication(s3client, bucket)
+
+	// Read destination, role and prefix
+	reader := utils.NewInputReader()
+	destination := reader.GetInputStr("Enter the destination bucket ARN, e.g. arn:aws:s3:::replica-bucket (empty to keep current):")
+	if len(destination) == 0 {
+		return
+	}
+	if !bucketARN.MatchString(destination) {
+		fmt.Printf("[%s] isn't a bucket ARN like arn:aws:s3:::replica-bucket\n", destination)
+		return
+	}
+	role := reader.GetInputStr("Enter the replication role ARN, e.g. arn:aws:iam::123456789012:role/replication:")
+	if !roleARN.MatchString(role) {
+		fmt.Printf("[%s] isn't a role ARN like arn:aws:iam::123456789012:role/replication\n", role)
+		return
+	}
+	prefix := reader.GetInputStr("Enter the key prefix to replicate (empty for all objects):")
+
+	// Put Bucket Replication, this replaces all existing rules
+	_, err = s3client.PutBucketReplication(
+		&s3.PutBucketReplicationInput{
+			Bucket: aws.String(bucket),
+			ReplicationConfiguration: &s3.ReplicationConfiguration{
+				Role: aws.String(role),
+				Rules: []*s3.ReplicationRule{
+					{
+						ID:                      aws.String("workshop-replication"),
+						Priority:                aws.Int64(1),
+						Status:                  aws.String(s3.ReplicationRuleStatusEnabled),
+						Filter:                  &s3.ReplicationRuleFilter{Prefix: aws.String(prefix)},
+						Destination:             &s3.Destination{Bucket: aws.String(destination)},
+						DeleteMarkerReplication: &s3.DeleteMarkerReplication{Status: aws.String(s3.DeleteMarkerReplicationStatusDisabled)},
+					},
+				},
+			},
+		})
+	utils.Check(err)
+	fmt.Printf("replicating bucket [%s] prefix [%s] to [%s]\n", bucket, prefix, destination)
+
+	// Print resulting replication rules
+	printReplication(s3client, bucket)
+}
+
+// printReplication prints the replication role and rules of bucket
+func printReplication(s3client *s3.S3, bucket string) {
+	resp, err := s3client.GetBucketReplication(&s3.GetBucketReplicationInput{Bucket: aws.String(bucket)})
+	if utils.IsAWSErrCode(err, utils.ErrCodeReplicationNotFound) {
+		fmt.Printf("bucket [%s] replication: none\n", bucket)
+		return
+	}
+	utils.Check(err)
+
+	conf := resp.ReplicationConfiguration
+	fmt.Printf("bucket [%s] replication role [%s]:\n", bucket, aws.StringValue(conf.Role))
+	for _, rule := range conf.Rules {
+		prefix := aws.StringValue(rule.Prefix)
+		if rule.Filter != nil {
+			prefix = aws.StringValue(rule.Filter.Prefix)
+		}
+		fmt.Printf("    [%s] %s: prefix [%s] to [%s]\n", aws.StringValue(rule.ID), aws.StringValue(rule.Status),
+			prefix, aws.StringValue(rule.Destination.Bucket))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"ops"
+	"os"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ContentSize is the size of the random test object
+const ContentSize = 1024
+
+// step is one check of the self-test
+type step struct {
+	name string
+	run  func() error
+}
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	if !selfTest(s3client, bucket) {
+		os.Exit(1)
+	}
+}
+
+// selfTest uploads, downloads, verifies and deletes a random object, printing
+// the outcome and timing of each step. It reports whether all steps passed.
+func selfTest(s3client *s3.S3, bucket string) bool {
+	// A unique key under selftest/ so no existing object is overwritten
+	suffix := make([]byte, 4)
+	content := make([]byte, ContentSize)
+	if _, err := rand.Read(suffix); err != nil {
+		fmt.Printf("FAIL setup: %v\n", err)
+		return false
+	}
+	if _, err := rand.Read(content); err != nil {
+		fmt.Printf("FAIL setup: %v\n", err)
+		return false
+	}
+	key := fmt.Sprintf("selftest/%d-%s", time.Now().Unix(), hex.EncodeToString(suffix))
+
+	// Remove the object even if a step in between fails
+	deleted := false
+	defer func() {
+		if !deleted {
+			if _, err := ops.DeleteObject(s3client, bucket, key, ""); err != nil {
+				fmt.Printf("failed to clean up object [%s/%s]: %v\n", bucket, key, err)
+			}
+		}
+	}()
+
+	var downloaded bytes.Buffer
+	steps := []step{
+		{"upload", func() error {
+			_, err := ops.PutObject(s3client, bucket, key, bytes.NewReader(content), nil)
+			return err
+		}},
+		{"download", func() error {
+			_, err := ops.GetObject(s3client, bucket, key, &downloaded, nil)
+			return err
+		}},
+		{"verify", func() error {
+			if !bytes.Equal(downloaded.Bytes(), content) {
+				return fmt.Errorf("downloaded %d bytes don't match the %d uploaded", downloaded.Len(), len(content))
+			}
+			return nil
+		}},
+		{"delete", func() error {
+			_, err := ops.DeleteObject(s3client, bucket, key, "")
+			deleted = err == nil
+			return err
+		}},
+	}
+
+	fmt.Printf("self-test of bucket [%s] with object [%s]\n", bucket, key)
+	for _, s := range steps {
+		start := time.Now()
+		err := s.run()
+		elapsed := int64(time.Since(start) / time.Millisecond)
+		if err != nil {
+			fmt.Printf("FAIL %-8s %5dms: %v\n", s.name, elapsed, err)
+			return false
+		}
+		fmt.Printf("PASS %-8s %5dms\n", s.name, elapsed)
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// byName sorts buckets by name
+type byName []*s3.Bucket
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return aws.StringValue(b[i].Name) < aws.StringValue(b[j].Name) }
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// List Buckets
+	resp, err := s3client.ListBuckets(&s3.ListBucketsInput{})
+	utils.Check(err)
+
+	owner := ""
+	if resp.Owner != nil {
+		owner = aws.StringValue(resp.Owner.DisplayName)
+	}
+	if len(resp.Buckets) == 0 {
+		fmt.Printf("no buckets owned by [%s]\n", owner)
+		return
+	}
+
+	sort.Sort(byName(resp.Buckets))
+	fmt.Printf("buckets owned by [%s]:\n", owner)
+	for _, bucket := range resp.Buckets {
+		fmt.Printf("%-25s %s\n", aws.TimeValue(bucket.CreationDate).Format(time.RFC3339), aws.StringValue(bucket.Name))
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Legal hold needs a bucket created with Object Lock enabled
+	lockResp, err := s3client.GetObjectLockConfiguration(
+		&s3.GetObjectLockConfigurationInput{
+			Bucket: aws.String(bucket),
+		})
+	if !utils.IsAWSErrCode(err, utils.ErrCodeObjectLockConfigurationNotFound) {
+		utils.Check(utils.ClassifyError(err, bucket, ""))
+	}
+	if err != nil || lockResp.ObjectLockConfiguration == nil ||
+		aws.StringValue(lockResp.ObjectLockConfiguration.ObjectLockEnabled) != s3.ObjectLockEnabledEnabled {
+		fmt.Printf("bucket [%s] doesn't have Object Lock enabled, legal hold is only available in buckets created with it\n", bucket)
+		return
+	}
+
+	// Read key
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+
+	// Get Object Legal Hold, an object that never had one reports no configuration
+	status := s3.ObjectLockLegalHoldStatusOff
+	resp, err := s3client.GetObjectLegalHold(
+		&s3.GetObjectLegalHoldInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	if !utils.IsAWSErrCode(err, utils.ErrCodeNoSuchObjectLockConfiguration) {
+		utils.Check(utils.ClassifyError(err, bucket, key))
+		if resp.LegalHold != nil {
+			status = aws.StringValue(resp.LegalHold.Status)
+		}
+	}
+	fmt.Printf("object [%s/%s] legal hold: [%s]\n", bucket, key, status)
+
+	// Read the new status
+	on, err := reader.GetInputBool("Should the object be under legal hold? (y/n)")
+	utils.Check(err)
+	newStatus := s3.ObjectLockLegalHoldStatusOff
+	if on {
+		newStatus = s3.ObjectLockLegalHoldStatusOn
+	}
+	if newStatus == status {
+		fmt.Printf("object [%s/%s] legal hold is already [%s]\n", bucket, key, status)
+		return
+	}
+
+	// Put Object Legal Hold
+	_, err = s3client.PutObjectLegalHold(
+		&s3.PutObjectLegalHoldInput{
+			Bucket:    aws.String(bucket),
+			Key:       aws.String(key),
+			LegalHold: &s3.ObjectLockLegalHold{Status: aws.String(newStatus)},
+		})
+	utils.Check(utils.ClassifyError(err, bucket, key))
+
+	fmt.Printf("object [%s/%s] legal hold set: [%s]\n", bucket, key, newStatus)
+}
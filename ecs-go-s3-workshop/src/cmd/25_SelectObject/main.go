@@ -0,0 +1,120 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Input formats of the queried object
+const (
+	FormatCSV  = "CSV"
+	FormatJSON = "JSON"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read key, format and query
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+	format := strings.ToUpper(reader.GetInputStr("Enter the object format (CSV with a header line, or JSON lines, empty for CSV):"))
+	if len(format) == 0 {
+		format = FormatCSV
+	}
+	input, output, err := serialization(format)
+	utils.Check(err)
+	expression := reader.GetInputStr("Enter the SQL expression (e.g. SELECT * FROM S3Object s LIMIT 10):")
+
+	// Select Object Content, results come back as a stream of events
+	resp, err := s3client.SelectObjectContent(
+		&s3.SelectObjectContentInput{
+			Bucket:              aws.String(bucket),
+			Key:                 aws.String(key),
+			Expression:          aws.String(expression),
+			ExpressionType:      aws.String(s3.ExpressionTypeSql),
+			InputSerialization:  input,
+			OutputSerialization: output,
+		})
+	if utils.IsAWSErrCode(err, utils.ErrCodeNotImplemented) {
+		fmt.Fprintln(os.Stderr, "the server doesn't support S3 Select")
+		os.Exit(1)
+	}
+	utils.Check(utils.ClassifyError(err, bucket, key))
+	defer resp.EventStream.Close()
+
+	// Records go to stdout so they can be piped, everything else to stderr
+	stats, err := streamRecords(resp.EventStream)
+	utils.Check(err)
+	if stats != nil {
+		fmt.Fprintf(os.Stderr, "selected from [%s/%s]: %d bytes scanned, %d processed, %d returned\n", bucket, key,
+			aws.Int64Value(stats.BytesScanned), aws.Int64Value(stats.BytesProcessed), aws.Int64Value(stats.BytesReturned))
+	}
+}
+
+// serialization returns the input and output serialization of format, results are in the same format
+func serialization(format string) (*s3.InputSerialization, *s3.OutputSerialization, error) {
+	switch format {
+	case FormatCSV:
+		return &s3.InputSerialization{CSV: &s3.CSVInput{FileHeaderInfo: aws.String(s3.FileHeaderInfoUse)}},
+			&s3.OutputSerialization{CSV: &s3.CSVOutput{}}, nil
+	case FormatJSON:
+		return &s3.InputSerialization{JSON: &s3.JSONInput{Type: aws.String(s3.JSONTypeLines)}},
+			&s3.OutputSerialization{JSON: &s3.JSONOutput{}}, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported format [%s], must be %s or %s", format, FormatCSV, FormatJSON)
+}
+
+// streamRecords writes the payload of Records events to stdout until the End event,
+// it returns the details of the Stats event. A stream without End is incomplete.
+func streamRecords(stream *s3.SelectObjectContentEventStream) (*s3.Stats, error) {
+	var stats *s3.Stats
+	ended := false
+	for event := range stream.Events() {
+		switch e := event.(type) {
+		case *s3.RecordsEvent:
+			if _, err := os.Stdout.Write(e.Payload); err != nil {
+				return stats, err
+			}
+		case *s3.StatsEvent:
+			stats = e.Details
+		case *s3.EndEvent:
+			ended = true
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return stats, err
+	}
+	if !ended {
+		return stats, errors.New("select result stream ended without an End event, the records are incomplete")
+	}
+	return stats, nil
+}
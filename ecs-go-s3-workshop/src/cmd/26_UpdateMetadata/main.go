@@ -0,0 +1,102 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"ops"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read prefix and the new metadata
+	reader := utils.NewInputReader()
+	prefix := reader.GetInputStr("Enter the prefix of the objects to update (empty for all):")
+	update := &ops.MetadataUpdate{
+		ContentType:  reader.GetInputStr("Enter the new content type (empty to keep):"),
+		CacheControl: reader.GetInputStr("Enter the new cache control, e.g. max-age=3600 (empty to keep):"),
+	}
+	update.Metadata, err = parseMetadata(reader.GetInputStr("Enter user metadata as key=value,... (key= removes it, empty to keep):"))
+	utils.Check(err)
+	if len(update.ContentType) == 0 && len(update.CacheControl) == 0 && len(update.Metadata) == 0 {
+		fmt.Println("nothing to update")
+		return
+	}
+
+	// List the objects to update
+	var keys []string
+	err = ops.ListObjects(s3client, bucket, prefix, func(obj *s3.Object) {
+		keys = append(keys, *obj.Key)
+	})
+	utils.Check(err)
+	if len(keys) == 0 {
+		fmt.Printf("no objects found under [%s/%s]\n", bucket, prefix)
+		return
+	}
+
+	if utils.DryRun() {
+		for _, key := range keys {
+			fmt.Printf("dry run: would update the metadata of [%s/%s]\n", bucket, key)
+		}
+		fmt.Printf("dry run: would update %d objects under [%s/%s]\n", len(keys), bucket, prefix)
+		return
+	}
+
+	// Copy each object onto itself with the new metadata
+	failed := 0
+	progress := utils.NewProgress("updated objects")
+	progress.Start()
+	for _, key := range keys {
+		if _, err := ops.UpdateMetadata(s3client, bucket, key, update); err != nil {
+			fmt.Printf("failed to update [%s/%s]: %v\n", bucket, key, err)
+			failed++
+		}
+		progress.Increment(1)
+	}
+	progress.Done()
+
+	fmt.Printf("updated the metadata of %d objects under [%s/%s], %d failed\n", len(keys)-failed, bucket, prefix, failed)
+}
+
+// parseMetadata parses key=value pairs separated by commas
+func parseMetadata(s string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if len(strings.TrimSpace(s)) == 0 {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || len(key) == 0 {
+			return nil, fmt.Errorf("invalid metadata [%s], expected key=value", pair)
+		}
+		metadata[key] = strings.TrimSpace(kv[1])
+	}
+	return metadata, nil
+}
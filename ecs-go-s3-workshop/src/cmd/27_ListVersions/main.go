@@ -0,0 +1,134 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// versionEntry is an object version or delete marker of a listing page
+type versionEntry struct {
+	key          string
+	versionID    string
+	isLatest     bool
+	deleteMarker bool
+	size         int64
+	lastModified time.Time
+}
+
+// byKeyNewestFirst orders entries like S3 does: by key, then newest version first
+type byKeyNewestFirst []versionEntry
+
+func (e byKeyNewestFirst) Len() int      { return len(e) }
+func (e byKeyNewestFirst) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e byKeyNewestFirst) Less(i, j int) bool {
+	if e[i].key != e[j].key {
+		return e[i].key < e[j].key
+	}
+	return e[i].lastModified.After(e[j].lastModified)
+}
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read prefix and page size
+	reader := utils.NewInputReader()
+	prefix := reader.GetInputStr("Enter the prefix (empty for none):")
+	maxKeysStr := reader.GetInputStr("Enter the page size (empty for the server default):")
+
+	params := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	}
+	if len(prefix) > 0 {
+		params.Prefix = aws.String(prefix)
+	}
+	if len(maxKeysStr) > 0 {
+		maxKeys, err := strconv.ParseInt(maxKeysStr, 10, 64)
+		utils.Check(err)
+		params.MaxKeys = aws.Int64(maxKeys)
+	}
+
+	fmt.Printf("%-40s %-40s %-8s %-6s %10s\n", "Key", "VersionId", "IsLatest", "Marker", "Size")
+	fmt.Printf("---------------------------------------- ---------------------------------------- -------- ------ ----------\n")
+
+	// List Object Versions page by page, a page can end in the middle of the
+	// versions of a key, so both the key and the version ID marker are needed
+	versions, deleteMarkers, pages := 0, 0, 0
+	for {
+		resp, err := s3client.ListObjectVersions(params)
+		utils.Check(utils.ClassifyError(err, bucket, ""))
+		pages++
+
+		for _, entry := range pageEntries(resp) {
+			fmt.Printf("%-40s %-40s %-8t %-6t %10d\n", entry.key, entry.versionID, entry.isLatest, entry.deleteMarker, entry.size)
+		}
+		versions += len(resp.Versions)
+		deleteMarkers += len(resp.DeleteMarkers)
+
+		if !aws.BoolValue(resp.IsTruncated) {
+			break
+		}
+		if len(aws.StringValue(resp.NextKeyMarker)) == 0 {
+			utils.Check(fmt.Errorf("listing of [%s/%s] is truncated without a next key marker", bucket, prefix))
+		}
+		params.KeyMarker = resp.NextKeyMarker
+		params.VersionIdMarker = resp.NextVersionIdMarker
+	}
+
+	fmt.Println()
+	fmt.Printf("[%s/%s]: %d versions, %d delete markers in %d pages\n", bucket, prefix, versions, deleteMarkers, pages)
+}
+
+// pageEntries merges the versions and delete markers of a page, which S3 returns
+// in separate lists, back into listing order
+func pageEntries(resp *s3.ListObjectVersionsOutput) []versionEntry {
+	entries := make([]versionEntry, 0, len(resp.Versions)+len(resp.DeleteMarkers))
+	for _, ver := range resp.Versions {
+		entries = append(entries, versionEntry{
+			key:          aws.StringValue(ver.Key),
+			versionID:    aws.StringValue(ver.VersionId),
+			isLatest:     aws.BoolValue(ver.IsLatest),
+			size:         aws.Int64Value(ver.Size),
+			lastModified: aws.TimeValue(ver.LastModified),
+		})
+	}
+	for _, marker := range resp.DeleteMarkers {
+		entries = append(entries, versionEntry{
+			key:          aws.StringValue(marker.Key),
+			versionID:    aws.StringValue(marker.VersionId),
+			isLatest:     aws.BoolValue(marker.IsLatest),
+			deleteMarker: true,
+			lastModified: aws.TimeValue(marker.LastModified),
+		})
+	}
+	sort.Stable(byKeyNewestFirst(entries))
+	return entries
+}
@@ -0,0 +1,76 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"ops"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read key, it must exist
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+	_, err = ops.StatObject(s3client, bucket, key)
+	utils.Check(err)
+
+	// Anyone with the URL can read the object afterwards, without credentials
+	fmt.Printf("WARNING: this makes [%s/%s] world-readable, anyone who knows its URL can download it\n", bucket, key)
+	confirmed, err := reader.GetInputBool("Make the object public? (y/n)")
+	utils.Check(err)
+	if !confirmed {
+		return
+	}
+
+	// Put Object ACL
+	_, err = s3client.PutObjectAcl(
+		&s3.PutObjectAclInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			ACL:    aws.String(s3.ObjectCannedACLPublicRead),
+		})
+	// With object ownership BucketOwnerEnforced the bucket rejects any ACL
+	if utils.IsAWSErrCode(err, utils.ErrCodeAccessControlListNotSupported) {
+		fmt.Printf("object ACLs are disabled on bucket [%s] (object ownership %s), share the object with a presigned URL (07_PresignedURL) or a bucket policy (17_BucketPolicy) instead\n",
+			bucket, s3.ObjectOwnershipBucketOwnerEnforced)
+		return
+	}
+	if utils.IsNotSupported(err) {
+		fmt.Println("object ACLs are not supported by this endpoint")
+		return
+	}
+	utils.Check(utils.ClassifyError(err, bucket, key))
+
+	// The public URL follows the addressing style the client uses
+	publicURL, err := utils.ObjectURL(aws.StringValue(s3client.Config.Endpoint), aws.BoolValue(s3client.Config.S3ForcePathStyle), bucket, key)
+	utils.Check(err)
+	fmt.Printf("made object [%s/%s] public-read\n", bucket, key)
+	fmt.Printf("public URL: %s\n", publicURL)
+}
@@ -0,0 +1,130 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"ops"
+	"strings"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// errUploadFailed ends the download once the upload it feeds has failed
+var errUploadFailed = errors.New("upload to the destination failed")
+
+// progressReader counts the bytes read through it in a Progress
+type progressReader struct {
+	r        io.Reader
+	progress *utils.Progress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.progress.Increment(n)
+	return n, err
+}
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Read source and destination, endpoints are names of s3.endpoints
+	reader := utils.NewInputReader()
+	fmt.Printf("configured endpoints: %s\n", strings.Join(utils.EndpointNames(config), ", "))
+	srcName := reader.GetInputStr("Enter the source endpoint:")
+	srcKey := reader.GetInputStr("Enter the source object key:")
+	dstName := reader.GetInputStr("Enter the destination endpoint:")
+	dstKey := reader.GetInputStr("Enter the destination object key (empty for the same key):")
+	if len(dstKey) == 0 {
+		dstKey = srcKey
+	}
+
+	// Get S3 clients and bucket names of both endpoints
+	srcClient, err := utils.GetS3ClientFor(config, srcName)
+	utils.Check(err)
+	srcBucket, err := utils.EndpointBucket(config, srcName)
+	utils.Check(err)
+	dstClient, err := utils.GetS3ClientFor(config, dstName)
+	utils.Check(err)
+	dstBucket, err := utils.EndpointBucket(config, dstName)
+	utils.Check(err)
+
+	// Head Object for the size and the headers to carry over
+	head, err := ops.StatObject(srcClient, srcBucket, srcKey)
+	utils.Check(err)
+	size := aws.Int64Value(head.ContentLength)
+
+	// Get Object writes into the pipe, which blocks until the upload reads from
+	// it, so the download never gets ahead of the destination by more than the
+	// parts the uploader holds (PartSize * Concurrency) and nothing touches disk
+	pr, pw := io.Pipe()
+	getErr := make(chan error, 1)
+	go func() {
+		_, err := ops.GetObject(srcClient, srcBucket, srcKey, pw, nil)
+		pw.CloseWithError(err)
+		getErr <- err
+	}()
+
+	// The uploader sends a single PutObject for objects up to PartSize and
+	// switches to a multipart upload, aborted on failure, for larger ones
+	uploader := s3manager.NewUploaderWithClient(dstClient, func(u *s3manager.Uploader) {
+		u.PartSize = utils.DefaultPartSize
+	})
+	copied := &utils.Counter{}
+	progress := utils.NewProgressWithCounter("copied bytes", copied)
+	progress.Start()
+	start := time.Now()
+	resp, err := uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(dstBucket),
+		Key:         aws.String(dstKey),
+		Body:        &progressReader{r: pr, progress: progress},
+		ContentType: head.ContentType,
+		Metadata:    head.Metadata,
+	})
+	// A failed upload stops reading, unblock the download so that it ends too.
+	// A failed download fails the upload as well, its error is the one to report.
+	pr.CloseWithError(errUploadFailed)
+	progress.Done()
+	if gerr := <-getErr; gerr != nil && gerr != errUploadFailed {
+		utils.Check(gerr)
+	}
+	utils.Check(utils.ClassifyError(err, dstBucket, dstKey))
+	elapsed := time.Since(start)
+
+	if copied.Snapshot() != size {
+		utils.Check(fmt.Errorf("copied %d bytes of [%s/%s], expected %d", copied.Snapshot(), srcBucket, srcKey, size))
+	}
+	mode := "single PUT"
+	if len(resp.UploadID) > 0 {
+		mode = "multipart upload"
+	}
+	fmt.Printf("copied [%s] [%s/%s] to [%s] [%s/%s] by %s: %d bytes in %s (%.2f MB/s)\n",
+		srcName, srcBucket, srcKey, dstName, dstBucket, dstKey, mode, size, elapsed, throughput(size, elapsed))
+}
+
+// throughput returns MB (2^20 bytes) per second
+func throughput(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) / (1 << 20) / elapsed.Seconds()
+}
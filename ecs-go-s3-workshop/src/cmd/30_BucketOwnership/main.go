@@ -0,0 +1,133 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Print current state
+	printControls(s3client, bucket)
+
+	// Read payer and object ownership
+	reader := utils.NewInputReader()
+	payer := ""
+	switch strings.ToUpper(reader.GetInputStr("Enter who pays for requests, R for Requester or B for BucketOwner (empty to keep current):")) {
+	case "R":
+		payer = s3.PayerRequester
+	case "B":
+		payer = s3.PayerBucketOwner
+	}
+	ownership := reader.GetInputStr("Enter the object ownership, " + strings.Join(ownershipModes, "/") + " (empty to keep current):")
+	if len(ownership) > 0 && !isOwnershipMode(ownership) {
+		fmt.Printf("[%s] isn't one of %s\n", ownership, strings.Join(ownershipModes, "/"))
+		return
+	}
+	if len(payer) == 0 && len(ownership) == 0 {
+		return
+	}
+
+	// Put Bucket Request Payment
+	if len(payer) > 0 {
+		_, err = s3client.PutBucketRequestPayment(
+			&s3.PutBucketRequestPaymentInput{
+				Bucket:                      aws.String(bucket),
+				RequestPaymentConfiguration: &s3.RequestPaymentConfiguration{Payer: aws.String(payer)},
+			})
+		if utils.IsNotSupported(err) {
+			fmt.Println("request payment is not supported by this endpoint")
+		} else {
+			utils.Check(err)
+			fmt.Printf("set bucket [%s] payer to [%s]\n", bucket, payer)
+		}
+	}
+
+	// Put Bucket Ownership Controls
+	if len(ownership) > 0 {
+		_, err = s3client.PutBucketOwnershipControls(
+			&s3.PutBucketOwnershipControlsInput{
+				Bucket: aws.String(bucket),
+				OwnershipControls: &s3.OwnershipControls{
+					Rules: []*s3.OwnershipControlsRule{{ObjectOwnership: aws.String(ownership)}},
+				},
+			})
+		if utils.IsNotSupported(err) {
+			fmt.Println("ownership controls are not supported by this endpoint")
+		} else {
+			utils.Check(err)
+			fmt.Printf("set bucket [%s] object ownership to [%s]\n", bucket, ownership)
+		}
+	}
+
+	// Print resulting state
+	printControls(s3client, bucket)
+}
+
+// ownershipModes are the accepted object ownership settings
+var ownershipModes = []string{
+	s3.ObjectOwnershipBucketOwnerEnforced,
+	s3.ObjectOwnershipBucketOwnerPreferred,
+	s3.ObjectOwnershipObjectWriter,
+}
+
+func isOwnershipMode(mode string) bool {
+	for _, m := range ownershipModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// printControls prints the payer and object ownership of bucket
+func printControls(s3client *s3.S3, bucket string) {
+	payment, err := s3client.GetBucketRequestPayment(&s3.GetBucketRequestPaymentInput{Bucket: aws.String(bucket)})
+	if utils.IsNotSupported(err) {
+		fmt.Printf("bucket [%s] payer: not supported by this endpoint\n", bucket)
+	} else {
+		utils.Check(err)
+		fmt.Printf("bucket [%s] payer: %s\n", bucket, aws.StringValue(payment.Payer))
+	}
+
+	controls, err := s3client.GetBucketOwnershipControls(&s3.GetBucketOwnershipControlsInput{Bucket: aws.String(bucket)})
+	switch {
+	case utils.IsNotSupported(err):
+		fmt.Printf("bucket [%s] object ownership: not supported by this endpoint\n", bucket)
+	case utils.IsAWSErrCode(err, utils.ErrCodeOwnershipControlsNotFound):
+		fmt.Printf("bucket [%s] object ownership: none\n", bucket)
+	default:
+		utils.Check(err)
+		for _, rule := range controls.OwnershipControls.Rules {
+			fmt.Printf("bucket [%s] object ownership: %s\n", bucket, aws.StringValue(rule.ObjectOwnership))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"html/template"
+	"os"
+	"sort"
+	"utils"
+)
+
+// form is a ready-to-use HTML upload form, S3 requires the file to be the last field
+var form = template.Must(template.New("form").Parse(`<html>
+<body>
+<form action="{{.URL}}" method="post" enctype="multipart/form-data">
+{{- range .Names}}
+  <input type="hidden" name="{{.}}" value="{{index $.Fields .}}">
+{{- end}}
+  <input type="file" name="file">
+  <input type="submit" value="Upload">
+</form>
+</body>
+</html>
+`))
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Read key, expiry and maximum size
+	reader := utils.NewInputReader()
+	key := reader.GetInputStr("Enter the object key:")
+	expiry, err := reader.GetInputDuration("How long should the form be valid? (e.g. 30m or 1d)")
+	utils.Check(err)
+	maxSize, err := reader.GetInputInt("Enter the maximum upload size in bytes:")
+	utils.Check(err)
+
+	// Presign the POST policy
+	post, err := utils.PresignPost(config, key, &utils.PostConditions{Expiry: expiry, MaxContentLength: maxSize})
+	utils.Check(err)
+
+	// Print the form with its fields in a stable order
+	names := make([]string, 0, len(post.Fields))
+	for name := range post.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	utils.Check(form.Execute(os.Stdout, struct {
+		*utils.PresignedPost
+		Names []string
+	}{post, names}))
+}
@@ -0,0 +1,77 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"ops"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/jacobstr/confer"
+)
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Read prefix
+	reader := utils.NewInputReader()
+	prefix := reader.GetInputStr("Enter the prefix (empty for none):")
+
+	// List Objects of every endpoint, one failing site doesn't stop the others
+	names := utils.EndpointNames(config)
+	if len(names) == 0 {
+		fmt.Println("no endpoints configured, set s3.endpoint or s3.endpoints")
+		return
+	}
+	failed := 0
+	for _, name := range names {
+		if err := listEndpoint(config, name, prefix); err != nil {
+			fmt.Printf("endpoint [%s] failed: %v\n", name, err)
+			failed++
+		}
+		fmt.Println()
+	}
+	fmt.Printf("listed %d endpoints, %d failed\n", len(names)-failed, failed)
+}
+
+// listEndpoint prints the objects under prefix in the bucket of the named endpoint
+func listEndpoint(config *confer.Config, name, prefix string) error {
+	// Get S3 client to the endpoint
+	s3client, err := utils.GetS3ClientFor(config, name)
+	if err != nil {
+		return err
+	}
+
+	// Get bucket name of the endpoint
+	bucket, err := utils.EndpointBucket(config, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("----------------- endpoint [%s] bucket [%s]\n", name, bucket)
+	stats := &utils.BucketStats{}
+	err = ops.ListObjects(s3client, bucket, prefix, func(obj *s3.Object) {
+		fmt.Printf("%30s %10d %s\n", *obj.LastModified, *obj.Size, *obj.Key)
+		stats.Add(*obj.Key, *obj.Size)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[%s/%s]: %s\n", bucket, prefix, stats)
+	return nil
+}
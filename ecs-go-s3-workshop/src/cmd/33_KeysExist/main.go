@@ -0,0 +1,86 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"utils"
+)
+
+// Workers is how many keys are headed at once
+const Workers = 8
+
+func main() {
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	// Read the keys file, one key per line
+	reader := utils.NewInputReader()
+	path := reader.GetInputStr("Enter the path of the file listing the keys:")
+	keys, err := readKeys(path)
+	utils.Check(err)
+	if len(keys) == 0 {
+		fmt.Printf("no keys in [%s]\n", path)
+		return
+	}
+
+	// Head Objects concurrently
+	exists, err := utils.ObjectsExist(s3client, bucket, keys, Workers)
+	utils.Check(err)
+
+	present := 0
+	for _, key := range keys {
+		status := "missing"
+		if exists[key] {
+			status = "present"
+			present++
+		}
+		fmt.Printf("[%s/%s]: %s\n", bucket, key, status)
+	}
+	fmt.Printf("%d of %d keys present in bucket [%s], %d missing\n", present, len(keys), bucket, len(keys)-present)
+}
+
+// readKeys returns the non-empty lines of the file at path, without duplicates
+func readKeys(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var keys []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key := strings.TrimRight(scanner.Text(), "\r")
+		if len(key) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
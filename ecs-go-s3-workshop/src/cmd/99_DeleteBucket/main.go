@@ -17,6 +17,7 @@ package main
 
 import (
 	"fmt"
+	"ops"
 	"utils"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -58,17 +59,22 @@ func main() {
 		}
 	}
 
+	if utils.DryRun() {
+		for _, obj := range objIdentifierSlice {
+			fmt.Printf("dry run: would delete [%s/%s] version [%s]\n", bucket, *obj.Key, aws.StringValue(obj.VersionId))
+		}
+		fmt.Printf("dry run: would delete bucket [%s]\n", bucket)
+		return
+	}
+
 	// Delete Objects/Versions
-	_, err = s3client.DeleteObjects(
-		&s3.DeleteObjectsInput{
-			Bucket: aws.String(bucket),
-			Delete: &s3.Delete{
-				Objects: objIdentifierSlice,
-			},
-		})
+	failed, err := ops.DeleteObjects(s3client, bucket, objIdentifierSlice, &ops.DeleteOptions{Attempts: config.GetInt("s3.delete_attempts")})
 	if err != nil {
 		fmt.Println(err.Error())
 	}
+	for _, e := range failed {
+		fmt.Printf("failed to delete [%s]: %s\n", aws.StringValue(e.Key), aws.StringValue(e.Message))
+	}
 
 	// Delete Bucket
 	_, err = s3client.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(bucket)})
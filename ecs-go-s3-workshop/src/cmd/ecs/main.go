@@ -0,0 +1,334 @@
+package main
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"ops"
+	"os"
+	"sort"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/jacobstr/confer"
+)
+
+// command is a subcommand of ecs
+type command struct {
+	args    string
+	minArgs int
+	maxArgs int
+	run     func(config *confer.Config, s3client *s3.S3, bucket string, args []string) error
+}
+
+var commands = map[string]*command{
+	"put":    {args: "<key> <file>", minArgs: 2, maxArgs: 2, run: put},
+	"get":    {args: "<key|s3://bucket/key> [file|-]", minArgs: 1, maxArgs: 2, run: get},
+	"resume": {args: "<key> <file>", minArgs: 2, maxArgs: 2, run: resume},
+	"delete": {args: "<key> [versionId]", minArgs: 1, maxArgs: 2, run: del},
+	"list":   {args: "[prefix]", minArgs: 0, maxArgs: 1, run: list},
+	"copy":   {args: "<srcKey|s3://bucket/key> <dstKey|s3://bucket/key>", minArgs: 2, maxArgs: 2, run: cp},
+	"stat":   {args: "<key>", minArgs: 1, maxArgs: 1, run: stat},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <command> [args]\n\nCommands:\n", os.Args[0])
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].args)
+	}
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	// Parse flags and find the command
+	flag.Usage = usage
+	utils.ParseFlags()
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[args[0]]
+	if !ok || len(args)-1 < cmd.minArgs || len(args)-1 > cmd.maxArgs {
+		usage()
+		os.Exit(2)
+	}
+
+	// Load config.yaml
+	config := utils.LoadConfig()
+
+	// Get S3 client to server
+	s3client, err := utils.GetS3Client(config)
+	utils.Check(err)
+
+	// Get bucket name from config
+	bucket := config.GetString("s3.demo_bucket_name")
+
+	utils.Check(cmd.run(config, s3client, bucket, args[1:]))
+}
+
+// put uploads a local file as key
+func put(config *confer.Config, s3client *s3.S3, bucket string, args []string) error {
+	key, path := args[0], args[1]
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = ops.PutObject(s3client, bucket, key, file,
+		&ops.PutOptions{
+			ChecksumAlgorithm:  utils.ChecksumAlgorithm(config),
+			ContentType:        config.GetString("s3.content_type"),
+			ContentDisposition: config.GetString("s3.content_disposition"),
+			CacheControl:       config.GetString("s3.cache_control"),
+		})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created object [%s/%s] from file [%s]\n", bucket, key, path)
+	return nil
+}
+
+// get downloads key to a local file, or streams it to stdout if no file or - is given.
+// Nothing else is printed to stdout so that it can be piped, e.g. into gzip.
+func get(config *confer.Config, s3client *s3.S3, bucket string, args []string) error {
+	bucket, key, err := objectArg(bucket, args[0])
+	if err != nil {
+		return err
+	}
+	// Stat first so that a missing object doesn't leave an empty file behind
+	head, err := ops.StatObject(s3client, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if len(args) > 1 && args[1] != "-" {
+		if file, err = os.Create(args[1]); err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	// With s3.cache_dir, unchanged objects are written from the cache
+	if cacheDir := config.GetString("s3.cache_dir"); len(cacheDir) > 0 {
+		err = getCached(cacheDir, s3client, bucket, key, head, w)
+	} else {
+		_, err = ops.GetObject(s3client, bucket, key, w, nil)
+	}
+	if err != nil && file != nil {
+		// Don't leave a partial file behind
+		file.Close()
+		os.Remove(args[1])
+	}
+	return err
+}
+
+// getCached writes key, whose current state is head, to w from the download cache
+// in cacheDir, it's only downloaded if the cache doesn't have its ETag or -force is given
+func getCached(cacheDir string, s3client *s3.S3, bucket, key string, head *s3.HeadObjectOutput, w io.Writer) error {
+	cache, err := utils.OpenDownloadCache(cacheDir)
+	if err != nil {
+		return err
+	}
+
+	path, ok := cache.Lookup(bucket, key, aws.StringValue(head.ETag))
+	if ok && !utils.Force() {
+		fmt.Fprintf(os.Stderr, "object [%s/%s] is unchanged, writing it from cache\n", bucket, key)
+	} else {
+		path, err = cache.Put(bucket, key, func(cw io.Writer) (string, error) {
+			resp, err := ops.GetObject(s3client, bucket, key, cw, nil)
+			if err != nil {
+				return "", err
+			}
+			return aws.StringValue(resp.ETag), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// resume completes a partial download of key to file by only getting the missing tail
+func resume(config *confer.Config, s3client *s3.S3, bucket string, args []string) error {
+	key, path := args[0], args[1]
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	head, err := ops.StatObject(s3client, bucket, key)
+	if err != nil {
+		return err
+	}
+	size := aws.Int64Value(head.ContentLength)
+	switch {
+	case fi.Size() == size:
+		fmt.Printf("file [%s] already has all %d bytes of [%s/%s]\n", path, size, bucket, key)
+		return nil
+	case fi.Size() > size:
+		return fmt.Errorf("file [%s] is larger than object [%s/%s], it's not a partial download of it", path, bucket, key)
+	}
+
+	// If-Match makes sure the tail comes from the same object the head was downloaded from
+	_, err = ops.GetObject(s3client, bucket, key, file,
+		&ops.GetOptions{
+			Range:      &ops.ByteRange{Start: fi.Size(), End: -1},
+			Conditions: &utils.Conditions{IfMatch: aws.StringValue(head.ETag)},
+		})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("resumed [%s/%s] to file [%s]: got bytes %d-%d\n", bucket, key, path, fi.Size(), size-1)
+	return nil
+}
+
+// del deletes key or one of its versions
+func del(config *confer.Config, s3client *s3.S3, bucket string, args []string) error {
+	key, versionID := args[0], ""
+	if len(args) > 1 {
+		versionID = args[1]
+	}
+
+	if utils.DryRun() {
+		fmt.Printf("dry run: would delete object [%s/%s] version [%s]\n", bucket, key, versionID)
+		return nil
+	}
+
+	_, err := ops.DeleteObject(s3client, bucket, key, versionID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("object [%s/%s] deleted\n", bucket, key)
+	return nil
+}
+
+// list prints all objects under an optional prefix
+func list(config *confer.Config, s3client *s3.S3, bucket string, args []string) error {
+	prefix := ""
+	if len(args) > 0 {
+		prefix = args[0]
+	}
+
+	// Ctrl-C stops listing after the page in flight, what was listed is still printed
+	ctx, stop := utils.SetupSignalContext()
+	defer stop()
+
+	// The stats are computed in the same pass that lists the objects
+	objects := []*utils.ObjectInfo{}
+	stats, err := utils.ComputeBucketStatsWithContext(ctx, s3client, bucket, prefix, func(obj *s3.Object) {
+		objects = append(objects, utils.NewObjectInfo(*obj.Key, *obj.Size, *obj.ETag, *obj.LastModified))
+	})
+	interrupted := ctx.Err() != nil
+	if err != nil && !interrupted {
+		return err
+	}
+
+	err = nil
+	if utils.IsJSONOutput() {
+		err = utils.PrintJSON(&utils.ObjectListing{Objects: objects, Stats: stats})
+	} else {
+		for _, obj := range objects {
+			fmt.Printf("%25s %10d %s\n", obj.LastModified, obj.Size, obj.Key)
+		}
+		fmt.Printf("[%s/%s]: %s\n", bucket, prefix, stats)
+	}
+	if interrupted {
+		fmt.Fprintf(os.Stderr, "interrupted: listed %d objects under [%s/%s]\n", len(objects), bucket, prefix)
+		os.Exit(utils.ExitInterrupted)
+	}
+	return err
+}
+
+// cp copies srcKey to dstKey, either may be an s3:// URI in another bucket
+func cp(config *confer.Config, s3client *s3.S3, bucket string, args []string) error {
+	srcBucket, srcKey, err := objectArg(bucket, args[0])
+	if err != nil {
+		return err
+	}
+	dstBucket, dstKey, err := objectArg(bucket, args[1])
+	if err != nil {
+		return err
+	}
+	_, err = ops.CopyObjectBetween(s3client, srcBucket, srcKey, dstBucket, dstKey)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("copied object [%s/%s] to [%s/%s]\n", srcBucket, srcKey, dstBucket, dstKey)
+	return nil
+}
+
+// objectArg returns the bucket and key of an s3://bucket/key argument, any
+// other argument is a key in bucket. Keys often contain slashes, so a plain
+// bucket/key isn't taken as URI here.
+func objectArg(bucket, arg string) (string, string, error) {
+	if utils.IsS3URI(arg) {
+		return utils.ParseS3ObjectURI(arg)
+	}
+	return bucket, arg, nil
+}
+
+// stat prints the metadata of key
+func stat(config *confer.Config, s3client *s3.S3, bucket string, args []string) error {
+	key := args[0]
+	resp, err := ops.StatObject(s3client, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	info := utils.NewObjectInfo(key, aws.Int64Value(resp.ContentLength), aws.StringValue(resp.ETag), aws.TimeValue(resp.LastModified))
+	info.ContentType = aws.StringValue(resp.ContentType)
+	info.StorageClass = aws.StringValue(resp.StorageClass)
+	info.Metadata = aws.StringValueMap(resp.Metadata)
+	info.ContentDisposition = aws.StringValue(resp.ContentDisposition)
+	info.CacheControl = aws.StringValue(resp.CacheControl)
+	if utils.IsJSONOutput() {
+		return utils.PrintJSON(info)
+	}
+	fmt.Printf("%s %d %s %s %s\n", info.Key, info.Size, info.ETag, info.LastModified, info.ContentType)
+	if len(info.ContentDisposition) > 0 {
+		fmt.Printf("Content-Disposition: %s\n", info.ContentDisposition)
+	}
+	if len(info.CacheControl) > 0 {
+		fmt.Printf("Cache-Control: %s\n", info.CacheControl)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+import (
+	"context"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// DeleteBatchSize is the maximum number of keys DeleteObjects accepts per request
+	DeleteBatchSize = 1000
+	// DefaultDeleteAttempts is used when DeleteOptions.Attempts isn't set
+	DefaultDeleteAttempts = 3
+	// DefaultDeleteBackoff is used when DeleteOptions.Backoff isn't set
+	DefaultDeleteBackoff = 500 * time.Millisecond
+)
+
+// DeleteOptions are optional settings of DeleteObjects
+type DeleteOptions struct {
+	// Attempts is how often a key failing with a retryable error is tried
+	Attempts int
+	// Backoff is the wait before the first retry, it doubles with each one
+	Backoff time.Duration
+	// Progress is called with the number of objects deleted so far
+	Progress func(deleted int)
+	// Context stops DeleteObjects before the next batch or retry once it's done
+	Context context.Context
+}
+
+// ListObjects pages through all objects under prefix calling fn for each
+func ListObjects(s3client utils.S3API, bucket, prefix string, fn func(obj *s3.Object)) error {
+	return ListObjectsWithContext(context.Background(), s3client, bucket, prefix, fn)
+}
+
+// ListObjectsWithContext is ListObjects that stops before the next page once ctx
+// is done, it then returns ctx.Err()
+func ListObjectsWithContext(ctx context.Context, s3client utils.S3API, bucket, prefix string, fn func(obj *s3.Object)) error {
+	params := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+	}
+	if len(prefix) > 0 {
+		params.SetPrefix(prefix)
+	}
+	err := s3client.ListObjectsV2Pages(params, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			fn(obj)
+		}
+		return ctx.Err() == nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	return utils.ClassifyError(err, bucket, "")
+}
+
+// DeleteObjects deletes objects in batches of DeleteBatchSize. Keys failing
+// with a retryable error are deleted again with backoff, up to opts.Attempts
+// times. It returns the keys that permanently failed; err is only set if a
+// whole request failed, or to opts.Context.Err() if it stopped early.
+func DeleteObjects(s3client utils.S3API, bucket string, objects []*s3.ObjectIdentifier, opts *DeleteOptions) ([]*s3.Error, error) {
+	if opts == nil {
+		opts = &DeleteOptions{}
+	}
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = DefaultDeleteAttempts
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var failed []*s3.Error
+	deleted := 0
+	for start := 0; start < len(objects); start += DeleteBatchSize {
+		end := start + DeleteBatchSize
+		if end > len(objects) {
+			end = len(objects)
+		}
+
+		pending := objects[start:end]
+		backoff := opts.Backoff
+		if backoff <= 0 {
+			backoff = DefaultDeleteBackoff
+		}
+		for attempt := 1; len(pending) > 0; attempt++ {
+			if attempt > 1 {
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+				}
+				backoff *= 2
+			}
+			if err := ctx.Err(); err != nil {
+				return failed, err
+			}
+
+			resp, err := s3client.DeleteObjects(
+				&s3.DeleteObjectsInput{
+					Bucket: aws.String(bucket),
+					Delete: &s3.Delete{
+						Objects: pending,
+						Quiet:   aws.Bool(true),
+					},
+				})
+			if err != nil {
+				return failed, utils.ClassifyError(err, bucket, "")
+			}
+
+			// Quiet mode only reports the keys that failed
+			deleted += len(pending) - len(resp.Errors)
+			pending = nil
+			for _, e := range resp.Errors {
+				if attempt < attempts && isRetryableDeleteError(e) {
+					pending = append(pending, &s3.ObjectIdentifier{Key: e.Key, VersionId: e.VersionId})
+				} else {
+					failed = append(failed, e)
+				}
+			}
+			if opts.Progress != nil {
+				opts.Progress(deleted)
+			}
+		}
+	}
+	return failed, nil
+}
+
+// isRetryableDeleteError reports whether a key that failed to delete may succeed when tried again
+func isRetryableDeleteError(e *s3.Error) bool {
+	switch aws.StringValue(e.Code) {
+	case utils.ErrCodeInternalError, utils.ErrCodeSlowDown:
+		return true
+	}
+	return false
+}
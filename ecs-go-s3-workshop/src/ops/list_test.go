@@ -0,0 +1,111 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"utils"
+	"utils/fake"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+type ListSuite struct {
+	s3client *fake.S3
+	objects  []*s3.ObjectIdentifier
+}
+
+var _ = Suite(&ListSuite{})
+
+func (s *ListSuite) SetUpTest(c *C) {
+	s.s3client = fake.NewS3("bucket")
+	s.objects = nil
+	for i := 0; i < 3; i++ {
+		key := fmt.Sprintf("key%d", i)
+		_, err := PutObject(s.s3client, "bucket", key, strings.NewReader("x"), nil)
+		c.Assert(err, IsNil)
+		s.objects = append(s.objects, &s3.ObjectIdentifier{Key: aws.String(key)})
+	}
+}
+
+func (s *ListSuite) TestDeleteObjectsRetriesFailedSubset(c *C) {
+	s.s3client.FailDeletes("key1", utils.ErrCodeSlowDown, utils.ErrCodeInternalError)
+
+	progress := []int{}
+	failed, err := DeleteObjects(s.s3client, "bucket", s.objects,
+		&DeleteOptions{Backoff: time.Millisecond, Progress: func(deleted int) { progress = append(progress, deleted) }})
+	c.Assert(err, IsNil)
+	c.Check(failed, HasLen, 0)
+	c.Check(progress, DeepEquals, []int{2, 2, 3})
+	c.Check(s.s3client.Keys("bucket"), HasLen, 0)
+}
+
+func (s *ListSuite) TestDeleteObjectsGivesUpAfterAttempts(c *C) {
+	s.s3client.FailDeletes("key1", utils.ErrCodeSlowDown, utils.ErrCodeSlowDown, utils.ErrCodeSlowDown)
+
+	failed, err := DeleteObjects(s.s3client, "bucket", s.objects, &DeleteOptions{Attempts: 2, Backoff: time.Millisecond})
+	c.Assert(err, IsNil)
+	c.Assert(failed, HasLen, 1)
+	c.Check(aws.StringValue(failed[0].Key), Equals, "key1")
+	c.Check(aws.StringValue(failed[0].Code), Equals, utils.ErrCodeSlowDown)
+}
+
+func (s *ListSuite) TestDeleteObjectsDoesntRetryAccessDenied(c *C) {
+	s.s3client.FailDeletes("key2", utils.ErrCodeAccessDenied)
+
+	failed, err := DeleteObjects(s.s3client, "bucket", s.objects, &DeleteOptions{Backoff: time.Millisecond})
+	c.Assert(err, IsNil)
+	c.Assert(failed, HasLen, 1)
+	c.Check(aws.StringValue(failed[0].Code), Equals, utils.ErrCodeAccessDenied)
+	c.Check(s.s3client.Keys("bucket"), DeepEquals, []string{"key2"})
+}
+
+func (s *ListSuite) TestListObjectsWithContextStopsWhenCanceled(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var keys []string
+	err := ListObjectsWithContext(ctx, s.s3client, "bucket", "", func(obj *s3.Object) {
+		keys = append(keys, aws.StringValue(obj.Key))
+		cancel()
+	})
+	c.Check(err, Equals, context.Canceled)
+	// The page being listed is still passed on
+	c.Check(keys, DeepEquals, []string{"key0", "key1", "key2"})
+}
+
+func (s *ListSuite) TestDeleteObjectsStopsBeforeRetryWhenCanceled(c *C) {
+	s.s3client.FailDeletes("key1", utils.ErrCodeSlowDown)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	failed, err := DeleteObjects(s.s3client, "bucket", s.objects,
+		&DeleteOptions{Backoff: time.Hour, Context: ctx, Progress: func(deleted int) { cancel() }})
+	c.Check(err, Equals, context.Canceled)
+	c.Check(failed, HasLen, 0)
+	c.Check(s.s3client.Keys("bucket"), DeepEquals, []string{"key1"})
+}
+
+func (s *ListSuite) TestDeleteObjectsCanceledDeletesNothing(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := DeleteObjects(s.s3client, "bucket", s.objects, &DeleteOptions{Context: ctx})
+	c.Check(err, Equals, context.Canceled)
+	c.Check(s.s3client.Keys("bucket"), HasLen, 3)
+}
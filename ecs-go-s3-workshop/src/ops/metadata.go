@@ -0,0 +1,100 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// MetadataUpdate are the headers UpdateMetadata changes, empty fields keep the current value
+type MetadataUpdate struct {
+	ContentType  string
+	CacheControl string
+	// Metadata is merged into the user metadata, an empty value removes the entry
+	Metadata map[string]string
+}
+
+// UpdateMetadata changes the metadata of key by copying it onto itself with
+// MetadataDirective REPLACE, which is what makes ECS accept a copy onto itself.
+// REPLACE drops whatever isn't sent, so the current headers (Content-Type,
+// Cache-Control, Content-Disposition, Content-Encoding, Content-Language,
+// Expires, website redirect) and storage class are read first and kept.
+// The copy fails with a 412 if key changed in between.
+func UpdateMetadata(s3client utils.S3API, bucket, key string, update *MetadataUpdate) (*s3.CopyObjectOutput, error) {
+	head, err := StatObject(s3client, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &s3.CopyObjectInput{
+		Bucket:                  aws.String(bucket),
+		Key:                     aws.String(key),
+		CopySource:              aws.String(CopySource(bucket, key)),
+		CopySourceIfMatch:       head.ETag,
+		MetadataDirective:       aws.String(s3.MetadataDirectiveReplace),
+		ContentType:             head.ContentType,
+		CacheControl:            head.CacheControl,
+		ContentDisposition:      head.ContentDisposition,
+		ContentEncoding:         head.ContentEncoding,
+		ContentLanguage:         head.ContentLanguage,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+		StorageClass:            head.StorageClass,
+		Metadata:                mergeMetadata(head.Metadata, update.Metadata),
+	}
+	// HeadObject returns Expires as sent, CopyObject only takes a valid date
+	if expires := aws.StringValue(head.Expires); len(expires) > 0 {
+		if t, err := http.ParseTime(expires); err == nil {
+			params.Expires = aws.Time(t)
+		} else {
+			log.Printf("warning: Expires [%s] of [%s/%s] isn't a valid date and is dropped", expires, bucket, key)
+		}
+	}
+	if len(update.ContentType) > 0 {
+		params.ContentType = aws.String(update.ContentType)
+	}
+	if len(update.CacheControl) > 0 {
+		params.CacheControl = aws.String(update.CacheControl)
+	}
+
+	resp, err := s3client.CopyObject(params)
+	return resp, utils.ClassifyError(err, bucket, key)
+}
+
+// mergeMetadata returns current with changes applied, an empty value in changes removes the entry.
+// Keys compare case-insensitively: HeadObject returns them canonicalized, e.g. Color for color.
+func mergeMetadata(current map[string]*string, changes map[string]string) map[string]*string {
+	merged := map[string]*string{}
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range changes {
+		for existing := range merged {
+			if strings.EqualFold(existing, k) {
+				delete(merged, existing)
+			}
+		}
+		if len(v) > 0 {
+			merged[k] = aws.String(v)
+		}
+	}
+	return merged
+}
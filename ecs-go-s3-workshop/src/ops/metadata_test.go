@@ -0,0 +1,95 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"strings"
+	"time"
+	"utils"
+	"utils/fake"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+var expires = time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+
+type MetadataSuite struct {
+	s3client *fake.S3
+}
+
+var _ = Suite(&MetadataSuite{})
+
+func (s *MetadataSuite) SetUpTest(c *C) {
+	s.s3client = fake.NewS3("bucket")
+	_, err := s.s3client.PutObject(&s3.PutObjectInput{
+		Bucket:                  aws.String("bucket"),
+		Key:                     aws.String("key"),
+		Body:                    strings.NewReader("content"),
+		ContentType:             aws.String("text/plain"),
+		ContentDisposition:      aws.String("attachment"),
+		ContentEncoding:         aws.String("gzip"),
+		ContentLanguage:         aws.String("de-DE"),
+		Expires:                 aws.Time(expires),
+		WebsiteRedirectLocation: aws.String("/moved.html"),
+		StorageClass:            aws.String("COLD"),
+		Metadata:                aws.StringMap(map[string]string{"owner": "alice", "tmp": "1"}),
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *MetadataSuite) TestUpdateMetadataKeepsWhatIsntChanged(c *C) {
+	_, err := UpdateMetadata(s.s3client, "bucket", "key", &MetadataUpdate{
+		CacheControl: "max-age=3600",
+		Metadata:     map[string]string{"team": "storage", "tmp": ""},
+	})
+	c.Assert(err, IsNil)
+
+	head, err := StatObject(s.s3client, "bucket", "key")
+	c.Assert(err, IsNil)
+	c.Check(aws.StringValue(head.ContentType), Equals, "text/plain")
+	c.Check(aws.StringValue(head.CacheControl), Equals, "max-age=3600")
+	c.Check(aws.StringValue(head.ContentDisposition), Equals, "attachment")
+	c.Check(aws.StringValue(head.ContentEncoding), Equals, "gzip")
+	c.Check(aws.StringValue(head.ContentLanguage), Equals, "de-DE")
+	c.Check(aws.StringValue(head.Expires), Equals, "Wed, 02 Jan 2030 15:04:05 GMT")
+	c.Check(aws.StringValue(head.WebsiteRedirectLocation), Equals, "/moved.html")
+	c.Check(aws.StringValue(head.StorageClass), Equals, "COLD")
+	c.Check(aws.StringValueMap(head.Metadata), DeepEquals, map[string]string{"owner": "alice", "team": "storage"})
+}
+
+func (s *MetadataSuite) TestMergeMetadataIgnoresCase(c *C) {
+	// HeadObject returns canonicalized keys
+	current := aws.StringMap(map[string]string{"Color": "red", "Owner": "alice"})
+	merged := mergeMetadata(current, map[string]string{"color": "", "owner": "bob", "size": "L"})
+	c.Check(aws.StringValueMap(merged), DeepEquals, map[string]string{"owner": "bob", "size": "L"})
+}
+
+func (s *MetadataSuite) TestUpdateMetadataContentType(c *C) {
+	_, err := UpdateMetadata(s.s3client, "bucket", "key", &MetadataUpdate{ContentType: "application/json"})
+	c.Assert(err, IsNil)
+
+	head, err := StatObject(s.s3client, "bucket", "key")
+	c.Assert(err, IsNil)
+	c.Check(aws.StringValue(head.ContentType), Equals, "application/json")
+	c.Check(aws.StringValueMap(head.Metadata), DeepEquals, map[string]string{"owner": "alice", "tmp": "1"})
+}
+
+func (s *MetadataSuite) TestUpdateMetadataMissingKey(c *C) {
+	_, err := UpdateMetadata(s.s3client, "bucket", "missing", &MetadataUpdate{ContentType: "text/html"})
+	c.Check(err, FitsTypeOf, &utils.NotFoundError{})
+}
@@ -0,0 +1,315 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DefaultContentType is used for content that can't be detected
+const DefaultContentType = "application/octet-stream"
+
+// PutOptions are optional settings of PutObject
+type PutOptions struct {
+	// Conditions are If-Match/If-None-Match preconditions
+	Conditions *utils.Conditions
+	// ChecksumAlgorithm is an additional checksum to send and verify
+	ChecksumAlgorithm string
+	// Metadata is user metadata sent as x-amz-meta-*
+	Metadata map[string]string
+	// ContentType overrides the content type detected by DetectContentType
+	ContentType string
+	// ContentDisposition such as attachment; filename="report.pdf" names downloads
+	ContentDisposition string
+	// CacheControl such as max-age=3600 tells clients and proxies how long to cache
+	CacheControl string
+}
+
+// GetOptions are optional settings of GetObject
+type GetOptions struct {
+	// Conditions are If-Match/If-None-Match/If-Modified-Since preconditions
+	Conditions *utils.Conditions
+	// VersionID selects a version other than the current one
+	VersionID string
+	// Range reads only part of the object
+	Range *ByteRange
+}
+
+// PutObject creates or replaces key with body.
+// Like the other operations, errors are classified with utils.ClassifyError.
+func PutObject(s3client utils.S3API, bucket, key string, body io.ReadSeeker, opts *PutOptions) (*s3.PutObjectOutput, error) {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	params := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	}
+	if len(opts.Metadata) > 0 {
+		params.SetMetadata(aws.StringMap(opts.Metadata))
+	}
+	contentType := opts.ContentType
+	if len(contentType) == 0 {
+		var err error
+		if contentType, err = DetectContentType(key, body); err != nil {
+			return nil, err
+		}
+	}
+	params.SetContentType(contentType)
+	if len(opts.ContentDisposition) > 0 {
+		if !ValidContentDisposition(opts.ContentDisposition) {
+			log.Printf("warning: Content-Disposition [%s] of [%s/%s] is neither attachment nor inline, clients may ignore it", opts.ContentDisposition, bucket, key)
+		}
+		params.SetContentDisposition(opts.ContentDisposition)
+	}
+	if len(opts.CacheControl) > 0 {
+		params.SetCacheControl(opts.CacheControl)
+	}
+	var reqOpts []request.Option
+	if opts.Conditions != nil {
+		reqOpts = append(reqOpts, opts.Conditions.PutOption())
+	}
+	checksum, err := utils.SetChecksum(params, opts.ChecksumAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s3client.PutObjectWithContext(aws.BackgroundContext(), params, reqOpts...)
+	if err != nil {
+		return nil, utils.ClassifyError(err, bucket, key)
+	}
+	return resp, utils.VerifyChecksum(opts.ChecksumAlgorithm, checksum, resp)
+}
+
+// ValidContentDisposition loosely checks that v is an attachment or inline
+// disposition, optionally followed by parameters such as a filename
+func ValidContentDisposition(v string) bool {
+	dispositionType := strings.ToLower(strings.TrimSpace(strings.SplitN(v, ";", 2)[0]))
+	return dispositionType == "attachment" || dispositionType == "inline"
+}
+
+// DetectContentType sniffs the first 512 bytes of body and rewinds it, falling
+// back to the extension of key when the content doesn't give it away. Text such
+// as CSS, JavaScript, JSON or CSV only sniffs as text/plain, so it falls back too.
+func DetectContentType(key string, body io.ReadSeeker) (string, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(body, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(buf[:n])
+	if contentType == DefaultContentType || strings.HasPrefix(contentType, "text/plain") {
+		if byExt := mime.TypeByExtension(path.Ext(key)); len(byExt) > 0 {
+			contentType = byExt
+		}
+	}
+	return contentType, nil
+}
+
+// GetObject writes the content of key to w, the returned output's Body is already consumed.
+// With opts.Range, a w that is an io.Seeker such as a file is written at the range's
+// offset; if the server ignores the range and answers with the whole object, at 0.
+func GetObject(s3client utils.S3API, bucket, key string, w io.Writer, opts *GetOptions) (*s3.GetObjectOutput, error) {
+	if opts == nil {
+		opts = &GetOptions{}
+	}
+
+	params := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if len(opts.VersionID) > 0 {
+		params.SetVersionId(opts.VersionID)
+	}
+	if opts.Conditions != nil {
+		opts.Conditions.ApplyToGet(params)
+	}
+	if opts.Range != nil {
+		if err := opts.Range.Validate(); err != nil {
+			return nil, err
+		}
+		params.SetRange(opts.Range.String())
+	}
+
+	resp, err := s3client.GetObject(params)
+	if err != nil {
+		return nil, utils.ClassifyError(err, bucket, key)
+	}
+	defer resp.Body.Close()
+
+	// A 206 Partial Content carries a Content-Range, a 200 is the whole object
+	if seeker, ok := w.(io.Seeker); ok && opts.Range != nil {
+		offset := int64(0)
+		if len(aws.StringValue(resp.ContentRange)) > 0 {
+			offset = opts.Range.Start
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return resp, utils.ClassifyError(err, bucket, key)
+}
+
+// DeleteObject deletes key, or only versionID of key if it's not empty
+func DeleteObject(s3client utils.S3API, bucket, key, versionID string) (*s3.DeleteObjectOutput, error) {
+	params := &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if len(versionID) > 0 {
+		params.SetVersionId(versionID)
+	}
+	resp, err := s3client.DeleteObject(params)
+	return resp, utils.ClassifyError(err, bucket, key)
+}
+
+// StatObject returns the metadata of key without its content
+func StatObject(s3client utils.S3API, bucket, key string) (*s3.HeadObjectOutput, error) {
+	resp, err := s3client.HeadObject(
+		&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	return resp, utils.ClassifyError(err, bucket, key)
+}
+
+// CopyObject copies srcKey to dstKey within bucket
+func CopyObject(s3client utils.S3API, bucket, srcKey, dstKey string) (*s3.CopyObjectOutput, error) {
+	return CopyObjectBetween(s3client, bucket, srcKey, bucket, dstKey)
+}
+
+// CopyObjectBetween copies srcBucket/srcKey to dstBucket/dstKey
+func CopyObjectBetween(s3client utils.S3API, srcBucket, srcKey, dstBucket, dstKey string) (*s3.CopyObjectOutput, error) {
+	resp, err := s3client.CopyObject(
+		&s3.CopyObjectInput{
+			Bucket:     aws.String(dstBucket),
+			Key:        aws.String(dstKey),
+			CopySource: aws.String(CopySource(srcBucket, srcKey)),
+		})
+	return resp, utils.ClassifyError(err, srcBucket, srcKey)
+}
+
+// CopySource returns the URL encoded bucket/key expected by x-amz-copy-source
+func CopySource(bucket, key string) string {
+	return (&url.URL{Path: bucket + "/" + key}).EscapedPath()
+}
+
+// PutObjectIfChanged is PutObject that skips the upload when key already has the
+// content of body, so that syncing again only transfers changed files. The content
+// is compared by the opts.ChecksumAlgorithm checksum if the server reports it, by
+// the ETag otherwise. An ETag that isn't the MD5 of the content, as with some
+// encryption modes, never matches, which only costs an unneeded upload.
+func PutObjectIfChanged(s3client utils.S3API, bucket, key string, body io.ReadSeeker, opts *PutOptions) (uploaded bool, err error) {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+	unchanged, err := hasContent(s3client, bucket, key, body, opts.ChecksumAlgorithm)
+	if err != nil || unchanged {
+		return false, err
+	}
+	if _, err := PutObject(s3client, bucket, key, body, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// hasContent reports whether key exists with the content of body, and rewinds body
+func hasContent(s3client utils.S3API, bucket, key string, body io.ReadSeeker, algorithm string) (bool, error) {
+	params := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if len(algorithm) > 0 {
+		params.ChecksumMode = aws.String(s3.ChecksumModeEnabled)
+	}
+	head, err := s3client.HeadObject(params)
+	if utils.IsAWSErrCode(err, utils.ErrCodeNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, utils.ClassifyError(err, bucket, key)
+	}
+
+	// Different sizes need no hashing
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	if size != aws.Int64Value(head.ContentLength) {
+		return false, nil
+	}
+
+	if remote := headChecksum(algorithm, head); len(remote) > 0 {
+		local, err := utils.ComputeChecksum(algorithm, body)
+		return local == remote, err
+	}
+
+	// A multipart ETag only matches if the parts were DefaultPartSize
+	etag := strings.Trim(aws.StringValue(head.ETag), "\"")
+	var local string
+	if _, ok := utils.MultipartETagParts(etag); ok {
+		local, err = utils.ComputeMultipartETag(body, utils.DefaultPartSize)
+	} else {
+		sum := md5.New()
+		_, err = io.Copy(sum, body)
+		local = hex.EncodeToString(sum.Sum(nil))
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return local == etag, nil
+}
+
+// headChecksum returns the algorithm checksum of a HeadObject response, empty if there's none
+func headChecksum(algorithm string, head *s3.HeadObjectOutput) string {
+	switch algorithm {
+	case s3.ChecksumAlgorithmCrc32:
+		return aws.StringValue(head.ChecksumCRC32)
+	case s3.ChecksumAlgorithmCrc32c:
+		return aws.StringValue(head.ChecksumCRC32C)
+	case s3.ChecksumAlgorithmSha1:
+		return aws.StringValue(head.ChecksumSHA1)
+	case s3.ChecksumAlgorithmSha256:
+		return aws.StringValue(head.ChecksumSHA256)
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"utils"
+	"utils/fake"
+
+	"github.com/aws/aws-sdk-go/aws"
+	. "gopkg.in/check.v1"
+)
+
+type ObjectSuite struct{}
+
+var _ = Suite(&ObjectSuite{})
+
+func (s *ObjectSuite) TestPutObjectDispositionAndCacheControl(c *C) {
+	s3client := fake.NewS3("bucket")
+	_, err := PutObject(s3client, "bucket", "report.pdf", strings.NewReader("%PDF-1.4"), &PutOptions{
+		ContentDisposition: `attachment; filename="report.pdf"`,
+		CacheControl:       "max-age=3600",
+	})
+	c.Assert(err, IsNil)
+
+	head, err := StatObject(s3client, "bucket", "report.pdf")
+	c.Assert(err, IsNil)
+	c.Check(aws.StringValue(head.ContentDisposition), Equals, `attachment; filename="report.pdf"`)
+	c.Check(aws.StringValue(head.CacheControl), Equals, "max-age=3600")
+}
+
+func (s *ObjectSuite) TestPutObjectWithoutDispositionAndCacheControl(c *C) {
+	s3client := fake.NewS3("bucket")
+	_, err := PutObject(s3client, "bucket", "key", strings.NewReader("content"), nil)
+	c.Assert(err, IsNil)
+
+	head, err := StatObject(s3client, "bucket", "key")
+	c.Assert(err, IsNil)
+	c.Check(aws.StringValue(head.ContentDisposition), Equals, "")
+	c.Check(aws.StringValue(head.CacheControl), Equals, "")
+}
+
+func (s *ObjectSuite) TestValidContentDisposition(c *C) {
+	for v, valid := range map[string]bool{
+		"attachment":                     true,
+		`attachment; filename="a b.txt"`: true,
+		" Inline ":                       true,
+		"inline; filename=a.txt":         true,
+		"":                               false,
+		"filename=a.txt":                 false,
+		"attachments; filename=a.txt":    false,
+		"form-data; name=\"field\"":      false,
+	} {
+		c.Check(ValidContentDisposition(v), Equals, valid, Commentf("%q", v))
+	}
+}
+
+func (s *ObjectSuite) TestCopyObjectBetweenBuckets(c *C) {
+	s3client := fake.NewS3("src", "dst")
+	_, err := PutObject(s3client, "src", "dir/key", strings.NewReader("content"), nil)
+	c.Assert(err, IsNil)
+
+	_, err = CopyObjectBetween(s3client, "src", "dir/key", "dst", "copy")
+	c.Assert(err, IsNil)
+
+	head, err := StatObject(s3client, "dst", "copy")
+	c.Assert(err, IsNil)
+	c.Check(aws.Int64Value(head.ContentLength), Equals, int64(len("content")))
+
+	_, err = CopyObjectBetween(s3client, "src", "missing", "dst", "copy")
+	c.Check(err, FitsTypeOf, &utils.NotFoundError{})
+}
+
+func (s *ObjectSuite) TestPutObjectIfChanged(c *C) {
+	s3client := fake.NewS3("bucket")
+	for _, t := range []struct {
+		content  string
+		uploaded bool
+	}{
+		{"version 1", true},  // new key
+		{"version 1", false}, // unchanged
+		{"version 2", true},  // same size, other content
+		{"version 10", true}, // other size
+		{"version 10", false},
+	} {
+		uploaded, err := PutObjectIfChanged(s3client, "bucket", "key", strings.NewReader(t.content), nil)
+		c.Assert(err, IsNil)
+		c.Check(uploaded, Equals, t.uploaded, Commentf("%s", t.content))
+
+		var buf bytes.Buffer
+		_, err = GetObject(s3client, "bucket", "key", &buf, nil)
+		c.Assert(err, IsNil)
+		c.Check(buf.String(), Equals, t.content)
+	}
+}
+
+func (s *ObjectSuite) TestPutObjectIfChangedMissingBucket(c *C) {
+	s3client := fake.NewS3("bucket")
+	uploaded, err := PutObjectIfChanged(s3client, "missing", "key", strings.NewReader("content"), nil)
+	c.Check(uploaded, Equals, false)
+	c.Check(err, FitsTypeOf, &utils.NotFoundError{})
+}
+
+func (s *ObjectSuite) TestDetectContentTypeSniffsBinary(c *C) {
+	// A PNG header wins over a misleading extension
+	body := bytes.NewReader([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	contentType, err := DetectContentType("image.txt", body)
+	c.Assert(err, IsNil)
+	c.Check(contentType, Equals, "image/png")
+}
+
+func (s *ObjectSuite) TestDetectContentTypeFallsBackToExtension(c *C) {
+	for key, expected := range map[string]string{
+		"style.css":  "text/css; charset=utf-8",
+		"data.json":  "application/json",
+		"notes.none": "text/plain; charset=utf-8",
+	} {
+		contentType, err := DetectContentType(key, strings.NewReader(`{"body": "text"}`))
+		c.Assert(err, IsNil)
+		c.Check(contentType, Equals, expected, Commentf("key %s", key))
+	}
+}
+
+func (s *ObjectSuite) TestDetectContentTypeRewindsBody(c *C) {
+	body := strings.NewReader(strings.Repeat("x", 1024))
+	_, err := DetectContentType("key", body)
+	c.Assert(err, IsNil)
+	offset, err := body.Seek(0, io.SeekCurrent)
+	c.Assert(err, IsNil)
+	c.Check(offset, Equals, int64(0))
+}
+
+func (s *ObjectSuite) TestPutObjectContentTypeOverride(c *C) {
+	s3client := fake.NewS3("bucket")
+	_, err := PutObject(s3client, "bucket", "style.css", strings.NewReader("body {}"), &PutOptions{ContentType: "text/x-custom"})
+	c.Assert(err, IsNil)
+
+	head, err := StatObject(s3client, "bucket", "style.css")
+	c.Assert(err, IsNil)
+	c.Check(aws.StringValue(head.ContentType), Equals, "text/x-custom")
+}
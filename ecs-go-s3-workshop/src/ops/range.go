@@ -0,0 +1,47 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ByteRange is an inclusive range of bytes of an object, an End below zero reads to the end
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Validate checks that the range starts at or after 0 and doesn't end before it starts
+func (r *ByteRange) Validate() error {
+	if r.Start < 0 {
+		return fmt.Errorf("invalid range: start %d is negative", r.Start)
+	}
+	if r.End >= 0 && r.Start > r.End {
+		return fmt.Errorf("invalid range: start %d is after end %d", r.Start, r.End)
+	}
+	return nil
+}
+
+// String returns the range as a Range header value, e.g. bytes=0-99 or bytes=100-
+func (r *ByteRange) String() string {
+	end := ""
+	if r.End >= 0 {
+		end = strconv.FormatInt(r.End, 10)
+	}
+	return fmt.Sprintf("bytes=%d-%s", r.Start, end)
+}
@@ -0,0 +1,72 @@
+package ops
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"utils"
+	"utils/fake"
+
+	. "gopkg.in/check.v1"
+)
+
+type RangeSuite struct{}
+
+var _ = Suite(&RangeSuite{})
+
+func (s *RangeSuite) TestString(c *C) {
+	c.Check((&ByteRange{Start: 0, End: 99}).String(), Equals, "bytes=0-99")
+	c.Check((&ByteRange{Start: 100, End: -1}).String(), Equals, "bytes=100-")
+}
+
+func (s *RangeSuite) TestValidate(c *C) {
+	c.Check((&ByteRange{Start: 5, End: 5}).Validate(), IsNil)
+	c.Check((&ByteRange{Start: 5, End: -1}).Validate(), IsNil)
+	c.Check((&ByteRange{Start: 6, End: 5}).Validate(), ErrorMatches, "invalid range: start 6 is after end 5")
+	c.Check((&ByteRange{Start: -1, End: 5}).Validate(), ErrorMatches, "invalid range: start -1 is negative")
+}
+
+func (s *RangeSuite) TestGetObjectRangeAtOffset(c *C) {
+	s3client := fake.NewS3("bucket")
+	_, err := PutObject(s3client, "bucket", "key", strings.NewReader("0123456789"), nil)
+	c.Assert(err, IsNil)
+
+	// A partial download of the first 4 bytes is completed with the missing tail
+	path := filepath.Join(c.MkDir(), "key")
+	c.Assert(ioutil.WriteFile(path, []byte("0123"), 0644), IsNil)
+	file, err := os.OpenFile(path, os.O_WRONLY, 0644)
+	c.Assert(err, IsNil)
+	resp, err := GetObject(s3client, "bucket", "key", file, &GetOptions{Range: &ByteRange{Start: 4, End: -1}})
+	c.Assert(err, IsNil)
+	c.Assert(file.Close(), IsNil)
+	c.Check(*resp.ContentRange, Equals, "bytes 4-9/10")
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "0123456789")
+}
+
+func (s *RangeSuite) TestGetObjectInvalidRange(c *C) {
+	s3client := fake.NewS3("bucket")
+	_, err := PutObject(s3client, "bucket", "key", strings.NewReader("0123"), nil)
+	c.Assert(err, IsNil)
+
+	_, err = GetObject(s3client, "bucket", "key", ioutil.Discard, &GetOptions{Range: &ByteRange{Start: 4, End: -1}})
+	c.Check(utils.IsAWSErrCode(err, utils.ErrCodeInvalidRange), Equals, true)
+}
@@ -0,0 +1,142 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CacheIndexFile is the name of the index in the cache directory
+const CacheIndexFile = "index.json"
+
+// cacheEntry is the cached content of an object
+type cacheEntry struct {
+	ETag string `json:"etag"`
+	File string `json:"file"`
+}
+
+// DownloadCache keeps downloaded objects in a directory, indexed by bucket/key
+// and ETag, so unchanged objects don't have to be downloaded again
+type DownloadCache struct {
+	mu      sync.Mutex
+	dir     string
+	entries map[string]*cacheEntry
+}
+
+// OpenDownloadCache opens the cache in dir, creating it if needed. A corrupt
+// index is logged and rebuilt empty, the objects are then downloaded again.
+func OpenDownloadCache(dir string) (*DownloadCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &DownloadCache{dir: dir, entries: map[string]*cacheEntry{}}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, CacheIndexFile))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &c.entries); err != nil {
+			log.Printf("cache index [%s] is corrupt, rebuilding it: %v", filepath.Join(dir, CacheIndexFile), err)
+			c.entries = map[string]*cacheEntry{}
+		}
+	}
+	return c, nil
+}
+
+// Lookup returns the cached file of bucket/key if it's cached with etag
+func (c *DownloadCache) Lookup(bucket, key, etag string) (path string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[cacheKey(bucket, key)]
+	if !ok || entry == nil || entry.ETag != strings.Trim(etag, "\"") {
+		return "", false
+	}
+	path = filepath.Join(c.dir, entry.File)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// Put caches bucket/key with the content fetch writes, fetch returns the ETag of
+// what it wrote. Nothing is cached if fetch fails.
+func (c *DownloadCache) Put(bucket, key string, fetch func(w io.Writer) (etag string, err error)) (path string, err error) {
+	name := cacheFileName(bucket, key)
+	tmp, err := ioutil.TempFile(c.dir, name+".tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	etag, err := fetch(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	path = filepath.Join(c.dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	c.entries[cacheKey(bucket, key)] = &cacheEntry{ETag: strings.Trim(etag, "\""), File: name}
+	return path, c.saveIndex()
+}
+
+// saveIndex writes the index atomically so that a crash doesn't corrupt it
+func (c *DownloadCache) saveIndex() error {
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(c.dir, CacheIndexFile+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, CacheIndexFile))
+}
+
+func cacheKey(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// cacheFileName is a file name for bucket/key, keys may contain any character
+func cacheFileName(bucket, key string) string {
+	sum := sha256.Sum256([]byte(cacheKey(bucket, key)))
+	return hex.EncodeToString(sum[:])
+}
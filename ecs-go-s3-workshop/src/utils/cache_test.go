@@ -0,0 +1,124 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type CacheSuite struct {
+	dir string
+}
+
+var _ = Suite(&CacheSuite{})
+
+func (s *CacheSuite) SetUpTest(c *C) {
+	s.dir = filepath.Join(c.MkDir(), "cache")
+}
+
+// fetchContent returns a fetch for DownloadCache.Put that writes content with etag
+func fetchContent(content, etag string) func(w io.Writer) (string, error) {
+	return func(w io.Writer) (string, error) {
+		_, err := io.WriteString(w, content)
+		return etag, err
+	}
+}
+
+func (s *CacheSuite) TestPutLookup(c *C) {
+	cache, err := OpenDownloadCache(s.dir)
+	c.Assert(err, IsNil)
+
+	_, ok := cache.Lookup("bucket", "dir/key", `"etag1"`)
+	c.Check(ok, Equals, false)
+
+	path, err := cache.Put("bucket", "dir/key", fetchContent("content", `"etag1"`))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "content")
+
+	cached, ok := cache.Lookup("bucket", "dir/key", `"etag1"`)
+	c.Check(ok, Equals, true)
+	c.Check(cached, Equals, path)
+
+	// A changed object is not served from the cache
+	_, ok = cache.Lookup("bucket", "dir/key", `"etag2"`)
+	c.Check(ok, Equals, false)
+}
+
+func (s *CacheSuite) TestIndexPersists(c *C) {
+	cache, err := OpenDownloadCache(s.dir)
+	c.Assert(err, IsNil)
+	_, err = cache.Put("bucket", "key", fetchContent("content", "etag1"))
+	c.Assert(err, IsNil)
+
+	reopened, err := OpenDownloadCache(s.dir)
+	c.Assert(err, IsNil)
+	_, ok := reopened.Lookup("bucket", "key", `"etag1"`)
+	c.Check(ok, Equals, true)
+}
+
+func (s *CacheSuite) TestCorruptIndexIsRebuilt(c *C) {
+	c.Assert(os.MkdirAll(s.dir, 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, CacheIndexFile), []byte("{not json"), 0644), IsNil)
+
+	cache, err := OpenDownloadCache(s.dir)
+	c.Assert(err, IsNil)
+	_, ok := cache.Lookup("bucket", "key", "etag1")
+	c.Check(ok, Equals, false)
+
+	_, err = cache.Put("bucket", "key", fetchContent("content", "etag1"))
+	c.Assert(err, IsNil)
+	reopened, err := OpenDownloadCache(s.dir)
+	c.Assert(err, IsNil)
+	_, ok = reopened.Lookup("bucket", "key", "etag1")
+	c.Check(ok, Equals, true)
+}
+
+func (s *CacheSuite) TestFailedFetchIsNotCached(c *C) {
+	cache, err := OpenDownloadCache(s.dir)
+	c.Assert(err, IsNil)
+
+	_, err = cache.Put("bucket", "key", func(w io.Writer) (string, error) {
+		io.WriteString(w, "partial")
+		return "", errors.New("connection reset")
+	})
+	c.Check(err, ErrorMatches, "connection reset")
+	_, ok := cache.Lookup("bucket", "key", "")
+	c.Check(ok, Equals, false)
+
+	// No partial download is left behind
+	files, err := ioutil.ReadDir(s.dir)
+	c.Assert(err, IsNil)
+	c.Check(files, HasLen, 0)
+}
+
+func (s *CacheSuite) TestMissingFileIsNotCached(c *C) {
+	cache, err := OpenDownloadCache(s.dir)
+	c.Assert(err, IsNil)
+	path, err := cache.Put("bucket", "key", fetchContent("content", "etag1"))
+	c.Assert(err, IsNil)
+	c.Assert(os.Remove(path), IsNil)
+
+	_, ok := cache.Lookup("bucket", "key", "etag1")
+	c.Check(ok, Equals, false)
+}
@@ -0,0 +1,113 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/jacobstr/confer"
+)
+
+// ChecksumAlgorithm returns s3.checksum_algorithm from config, empty means no additional checksum
+func ChecksumAlgorithm(config *confer.Config) string {
+	return strings.ToUpper(config.GetString("s3.checksum_algorithm"))
+}
+
+// ComputeChecksum returns the base64 encoded checksum of r and rewinds it
+func ComputeChecksum(algorithm string, r io.ReadSeeker) (string, error) {
+	var h hash.Hash
+	switch algorithm {
+	case s3.ChecksumAlgorithmCrc32:
+		h = crc32.NewIEEE()
+	case s3.ChecksumAlgorithmCrc32c:
+		h = crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case s3.ChecksumAlgorithmSha1:
+		h = sha1.New()
+	case s3.ChecksumAlgorithmSha256:
+		h = sha256.New()
+	default:
+		return "", fmt.Errorf("unsupported checksum algorithm [%s]", algorithm)
+	}
+
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// SetChecksum computes the checksum of input.Body and sends it along with the algorithm,
+// it returns the computed checksum or empty if algorithm is empty
+func SetChecksum(input *s3.PutObjectInput, algorithm string) (string, error) {
+	if len(algorithm) == 0 {
+		return "", nil
+	}
+
+	sum, err := ComputeChecksum(algorithm, input.Body)
+	if err != nil {
+		return "", err
+	}
+
+	input.SetChecksumAlgorithm(algorithm)
+	switch algorithm {
+	case s3.ChecksumAlgorithmCrc32:
+		input.ChecksumCRC32 = aws.String(sum)
+	case s3.ChecksumAlgorithmCrc32c:
+		input.ChecksumCRC32C = aws.String(sum)
+	case s3.ChecksumAlgorithmSha1:
+		input.ChecksumSHA1 = aws.String(sum)
+	case s3.ChecksumAlgorithmSha256:
+		input.ChecksumSHA256 = aws.String(sum)
+	}
+	return sum, nil
+}
+
+// VerifyChecksum checks the checksum returned by the server matches expected
+func VerifyChecksum(algorithm, expected string, output *s3.PutObjectOutput) error {
+	if len(algorithm) == 0 {
+		return nil
+	}
+
+	var actual *string
+	switch algorithm {
+	case s3.ChecksumAlgorithmCrc32:
+		actual = output.ChecksumCRC32
+	case s3.ChecksumAlgorithmCrc32c:
+		actual = output.ChecksumCRC32C
+	case s3.ChecksumAlgorithmSha1:
+		actual = output.ChecksumSHA1
+	case s3.ChecksumAlgorithmSha256:
+		actual = output.ChecksumSHA256
+	}
+
+	if actual == nil {
+		return fmt.Errorf("server returned no %s checksum, expected [%s]", algorithm, expected)
+	}
+	if *actual != expected {
+		return fmt.Errorf("%s checksum mismatch: computed [%s], server returned [%s]", algorithm, expected, *actual)
+	}
+	return nil
+}
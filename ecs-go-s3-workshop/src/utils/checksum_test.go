@@ -0,0 +1,90 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+type ChecksumSuite struct{}
+
+var _ = Suite(&ChecksumSuite{})
+
+// Expected checksums are the base64 encoded digests of "hello world"
+func (s *ChecksumSuite) TestComputeChecksum(c *C) {
+	for algorithm, expected := range map[string]string{
+		s3.ChecksumAlgorithmCrc32:  "DUoRhQ==",
+		s3.ChecksumAlgorithmCrc32c: "yZRlqg==",
+		s3.ChecksumAlgorithmSha1:   "Kq5sNclPz7QV2+lfQIuc6R7oRu0=",
+		s3.ChecksumAlgorithmSha256: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+	} {
+		sum, err := ComputeChecksum(algorithm, strings.NewReader("hello world"))
+		c.Assert(err, IsNil)
+		c.Check(sum, Equals, expected, Commentf("algorithm %s", algorithm))
+	}
+}
+
+func (s *ChecksumSuite) TestComputeChecksumUnsupported(c *C) {
+	_, err := ComputeChecksum("MD5", strings.NewReader("hello world"))
+	c.Check(err, ErrorMatches, "unsupported checksum algorithm \\[MD5\\]")
+}
+
+func (s *ChecksumSuite) TestSetChecksum(c *C) {
+	for algorithm, field := range map[string]func(*s3.PutObjectInput) *string{
+		s3.ChecksumAlgorithmCrc32:  func(input *s3.PutObjectInput) *string { return input.ChecksumCRC32 },
+		s3.ChecksumAlgorithmCrc32c: func(input *s3.PutObjectInput) *string { return input.ChecksumCRC32C },
+		s3.ChecksumAlgorithmSha1:   func(input *s3.PutObjectInput) *string { return input.ChecksumSHA1 },
+		s3.ChecksumAlgorithmSha256: func(input *s3.PutObjectInput) *string { return input.ChecksumSHA256 },
+	} {
+		input := &s3.PutObjectInput{Body: strings.NewReader("hello world")}
+		sum, err := SetChecksum(input, algorithm)
+		c.Assert(err, IsNil)
+		c.Check(aws.StringValue(input.ChecksumAlgorithm), Equals, algorithm)
+		c.Check(aws.StringValue(field(input)), Equals, sum, Commentf("algorithm %s", algorithm))
+
+		// The body is rewound for the upload
+		expected, err := ComputeChecksum(algorithm, input.Body)
+		c.Assert(err, IsNil)
+		c.Check(sum, Equals, expected)
+	}
+}
+
+func (s *ChecksumSuite) TestVerifyChecksum(c *C) {
+	output := &s3.PutObjectOutput{ChecksumSHA256: aws.String("uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=")}
+	c.Check(VerifyChecksum(s3.ChecksumAlgorithmSha256, "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", output), IsNil)
+
+	err := VerifyChecksum(s3.ChecksumAlgorithmSha256, "other", output)
+	c.Check(err, ErrorMatches, "SHA256 checksum mismatch: computed \\[other\\], server returned \\[uU0n.*\\]")
+}
+
+func (s *ChecksumSuite) TestVerifyChecksumMissing(c *C) {
+	err := VerifyChecksum(s3.ChecksumAlgorithmCrc32, "DUoRhQ==", &s3.PutObjectOutput{})
+	c.Check(err, ErrorMatches, "server returned no CRC32 checksum, expected \\[DUoRhQ==\\]")
+}
+
+func (s *ChecksumSuite) TestChecksumDisabled(c *C) {
+	input := &s3.PutObjectInput{Body: strings.NewReader("hello world")}
+	sum, err := SetChecksum(input, "")
+	c.Assert(err, IsNil)
+	c.Check(sum, Equals, "")
+	c.Check(input.ChecksumAlgorithm, IsNil)
+
+	c.Check(VerifyChecksum("", "", &s3.PutObjectOutput{}), IsNil)
+}
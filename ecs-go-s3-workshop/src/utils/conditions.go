@@ -0,0 +1,88 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Conditions are optional If-* preconditions of a GET or PUT, empty fields aren't sent
+type Conditions struct {
+	IfMatch         string
+	IfNoneMatch     string
+	IfModifiedSince time.Time
+}
+
+// GetInputConditions reads Conditions, If-Modified-Since is only asked for when withModifiedSince is set
+func (r *InputReader) GetInputConditions(withModifiedSince bool) (*Conditions, error) {
+	cond := &Conditions{
+		IfMatch:     r.GetInputStr("Enter If-Match ETag (empty for none):"),
+		IfNoneMatch: r.GetInputStr("Enter If-None-Match ETag, or * (empty for none):"),
+	}
+	if withModifiedSince {
+		since := r.GetInputStr("Enter If-Modified-Since as RFC3339, e.g. 2016-01-02T15:04:05Z (empty for none):")
+		if len(since) > 0 {
+			t, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				return nil, err
+			}
+			cond.IfModifiedSince = t
+		}
+	}
+	return cond, nil
+}
+
+// ApplyToGet sets the conditions on a GetObjectInput
+func (c *Conditions) ApplyToGet(input *s3.GetObjectInput) {
+	if len(c.IfMatch) > 0 {
+		input.SetIfMatch(c.IfMatch)
+	}
+	if len(c.IfNoneMatch) > 0 {
+		input.SetIfNoneMatch(c.IfNoneMatch)
+	}
+	if !c.IfModifiedSince.IsZero() {
+		input.SetIfModifiedSince(c.IfModifiedSince)
+	}
+}
+
+// PutOption returns a request option that sends the conditions with a PutObject,
+// PUT has no If-Modified-Since. PutObjectInput has no If-Match/If-None-Match
+// fields, so the headers are set once the request is built, before it's signed.
+func (c *Conditions) PutOption() request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Build.PushBack(func(r *request.Request) {
+			if len(c.IfMatch) > 0 {
+				r.HTTPRequest.Header.Set("If-Match", c.IfMatch)
+			}
+			if len(c.IfNoneMatch) > 0 {
+				r.HTTPRequest.Header.Set("If-None-Match", c.IfNoneMatch)
+			}
+		})
+	}
+}
+
+// IsPreconditionFailed reports whether err is a 412 Precondition Failed
+func IsPreconditionFailed(err error) bool {
+	return awsStatusCode(err) == http.StatusPreconditionFailed || IsAWSErrCode(err, ErrCodePreconditionFailed)
+}
+
+// IsNotModified reports whether err is a 304 Not Modified of a conditional GET
+func IsNotModified(err error) bool {
+	return awsStatusCode(err) == http.StatusNotModified || IsAWSErrCode(err, ErrCodeNotModified)
+}
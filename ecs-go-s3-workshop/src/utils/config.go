@@ -16,16 +16,111 @@ package utils
  */
 import (
 	"log"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/jacobstr/confer"
 )
 
-// LoadConfig loads default config.yaml file
+const (
+	// DefaultConfigPath is used when neither -config nor ConfigEnvVar is set
+	DefaultConfigPath = "config.yaml"
+	// ConfigEnvVar is the environment variable holding the config path
+	ConfigEnvVar = "ECS_SAMPLE_CONFIG"
+	// ConfigPollInterval is how often WatchConfig checks the config file for changes
+	ConfigPollInterval = 200 * time.Millisecond
+	// ConfigDebounce is how long the config file must stay unchanged before WatchConfig reloads it
+	ConfigDebounce = 500 * time.Millisecond
+)
+
+// ConfigPath resolves the config path from -config flag, then ConfigEnvVar, then DefaultConfigPath
+func ConfigPath() (string, error) {
+	ParseFlags()
+
+	path := *configFlag
+	if len(path) == 0 {
+		path = os.Getenv(ConfigEnvVar)
+	}
+	if len(path) == 0 {
+		path = DefaultConfigPath
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", &ConfigError{Path: path, Err: err}
+	}
+	return path, nil
+}
+
+// LoadConfig loads config file resolved by ConfigPath, exiting with the ConfigError if it can't
 func LoadConfig() *confer.Config {
-	config := confer.NewConfig()
-	err := config.ReadPaths("config.yaml")
+	path, err := ConfigPath()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config, err := readConfig(path)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return config
 }
+
+// WatchConfig calls onChange with the reloaded config whenever the file at path
+// changes, until stop is called. Writes in quick succession, such as an editor
+// saving, are debounced into one reload; a file that fails to load is logged and
+// skipped so onChange only ever sees valid config.
+func WatchConfig(path string, onChange func(config *confer.Config)) (stop func(), err error) {
+	return watchConfig(path, ConfigPollInterval, ConfigDebounce, onChange)
+}
+
+func watchConfig(path string, interval, debounce time.Duration, onChange func(config *confer.Config)) (func(), error) {
+	last, err := os.Stat(path)
+	if err != nil {
+		return nil, &ConfigError{Path: path, Err: err}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var changedAt time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				// Restart the quiet period on every change, reload once it has passed
+				if fi, err := os.Stat(path); err == nil && (fi.Size() != last.Size() || !fi.ModTime().Equal(last.ModTime())) {
+					last = fi
+					changedAt = now
+					continue
+				}
+				if changedAt.IsZero() || now.Sub(changedAt) < debounce {
+					continue
+				}
+				changedAt = time.Time{}
+
+				config, err := readConfig(path)
+				if err != nil {
+					log.Printf("failed to reload config file [%s], keeping the previous one: %v", path, err)
+					continue
+				}
+				onChange(config)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }, nil
+}
+
+// readConfig reads the config file at path
+func readConfig(path string) (*confer.Config, error) {
+	config := confer.NewConfig()
+	if err := config.ReadPaths(path); err != nil {
+		return nil, &ConfigError{Path: path, Err: err}
+	}
+	return config, nil
+}
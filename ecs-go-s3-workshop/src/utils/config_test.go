@@ -0,0 +1,134 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jacobstr/confer"
+	. "gopkg.in/check.v1"
+)
+
+type ConfigSuite struct{}
+
+var _ = Suite(&ConfigSuite{})
+
+func (s *ConfigSuite) TestWatchConfigDebounces(c *C) {
+	path := filepath.Join(c.MkDir(), "config.yaml")
+	c.Assert(ioutil.WriteFile(path, []byte("s3:\n  region: us-east-1\n"), 0644), IsNil)
+
+	reloads := make(chan *confer.Config, 10)
+	stop, err := watchConfig(path, 5*time.Millisecond, 50*time.Millisecond, func(config *confer.Config) {
+		reloads <- config
+	})
+	c.Assert(err, IsNil)
+	defer stop()
+
+	// Several writes of one save end up in a single reload
+	for _, region := range []string{"us-west-1", "us-west-2", "eu-central-1"} {
+		c.Assert(ioutil.WriteFile(path, []byte("s3:\n  region: "+region+"\n"), 0644), IsNil)
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-reloads:
+	case <-time.After(time.Second):
+		c.Fatal("config wasn't reloaded")
+	}
+	select {
+	case <-reloads:
+		c.Fatal("config was reloaded more than once")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func (s *ConfigSuite) TestWatchConfigStop(c *C) {
+	path := filepath.Join(c.MkDir(), "config.yaml")
+	c.Assert(ioutil.WriteFile(path, []byte("s3:\n"), 0644), IsNil)
+
+	reloads := make(chan *confer.Config, 10)
+	stop, err := watchConfig(path, 5*time.Millisecond, 20*time.Millisecond, func(config *confer.Config) {
+		reloads <- config
+	})
+	c.Assert(err, IsNil)
+	stop()
+	stop()
+
+	c.Assert(ioutil.WriteFile(path, []byte("s3:\n  region: us-west-1\n"), 0644), IsNil)
+	select {
+	case <-reloads:
+		c.Fatal("config was reloaded after stop")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (s *ConfigSuite) TestWatchConfigMissingFile(c *C) {
+	_, err := WatchConfig(filepath.Join(c.MkDir(), "missing.yaml"), func(*confer.Config) {})
+	c.Check(err, ErrorMatches, "config \\[.*missing.yaml\\]: .*")
+}
+
+func (s *ConfigSuite) TestConfigPathPrecedence(c *C) {
+	dir := c.MkDir()
+	for _, name := range []string{"flag.yaml", "env.yaml", DefaultConfigPath} {
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, name), []byte("s3:\n"), 0644), IsNil)
+	}
+	// DefaultConfigPath is relative to the working directory
+	wd, err := os.Getwd()
+	c.Assert(err, IsNil)
+	c.Assert(os.Chdir(dir), IsNil)
+	defer os.Chdir(wd)
+
+	savedFlag := *configFlag
+	defer func() { *configFlag = savedFlag }()
+	savedEnv, envSet := os.LookupEnv(ConfigEnvVar)
+	defer func() {
+		if envSet {
+			os.Setenv(ConfigEnvVar, savedEnv)
+		} else {
+			os.Unsetenv(ConfigEnvVar)
+		}
+	}()
+
+	for _, t := range []struct {
+		flag     string
+		env      string
+		expected string
+	}{
+		{"flag.yaml", "env.yaml", "flag.yaml"},
+		{"", "env.yaml", "env.yaml"},
+		{"", "", DefaultConfigPath},
+	} {
+		*configFlag = t.flag
+		os.Setenv(ConfigEnvVar, t.env)
+		path, err := ConfigPath()
+		c.Assert(err, IsNil)
+		c.Check(path, Equals, t.expected, Commentf("flag %q, env %q", t.flag, t.env))
+	}
+}
+
+func (s *ConfigSuite) TestConfigPathMissingFile(c *C) {
+	savedFlag := *configFlag
+	defer func() { *configFlag = savedFlag }()
+
+	*configFlag = filepath.Join(c.MkDir(), "missing.yaml")
+	_, err := ConfigPath()
+	configErr, ok := err.(*ConfigError)
+	c.Assert(ok, Equals, true, Commentf("%T", err))
+	c.Check(configErr.Path, Equals, *configFlag)
+	c.Check(os.IsNotExist(configErr.Err), Equals, true)
+}
@@ -0,0 +1,34 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import "sync/atomic"
+
+// Counter is a count that many goroutines can add to, its zero value is ready to use
+type Counter struct {
+	// n is first to be 64-bit aligned for atomic access on 32-bit platforms
+	n int64
+}
+
+// Add adds delta to the count and returns the new count
+func (c *Counter) Add(delta int64) int64 {
+	return atomic.AddInt64(&c.n, delta)
+}
+
+// Snapshot returns the current count
+func (c *Counter) Snapshot() int64 {
+	return atomic.LoadInt64(&c.n)
+}
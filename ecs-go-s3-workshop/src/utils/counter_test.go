@@ -0,0 +1,75 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+type CounterSuite struct{}
+
+var _ = Suite(&CounterSuite{})
+
+const (
+	testWorkers   = 16
+	testPerWorker = 1000
+)
+
+func (s *CounterSuite) TestCounterConcurrentAdd(c *C) {
+	counter := &Counter{}
+	var wg sync.WaitGroup
+	for i := 0; i < testWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < testPerWorker; j++ {
+				counter.Add(1)
+				counter.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+	c.Check(counter.Snapshot(), Equals, int64(testWorkers*testPerWorker))
+}
+
+func (s *CounterSuite) TestProgressWithCounterFromWorkers(c *C) {
+	var out bytes.Buffer
+	counter := &Counter{}
+	progress := NewProgressWithCounter("uploaded files", counter)
+	progress.out = &out
+	progress.enabled = true
+
+	progress.Start()
+	var wg sync.WaitGroup
+	for i := 0; i < testWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < testPerWorker; j++ {
+				progress.Increment(1)
+			}
+		}()
+	}
+	wg.Wait()
+	progress.Done()
+
+	c.Check(counter.Snapshot(), Equals, int64(testWorkers*testPerWorker))
+	c.Check(strings.HasSuffix(out.String(), "\ruploaded files: 16000 done\n"), Equals, true)
+}
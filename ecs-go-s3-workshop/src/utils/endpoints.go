@@ -0,0 +1,99 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jacobstr/confer"
+)
+
+// DefaultEndpoint names the endpoint of the top-level s3 settings
+const DefaultEndpoint = "default"
+
+// endpointKeys are the settings of an s3.endpoints entry that never fall back
+// to the top-level ones, so that credentials aren't sent to another site
+var endpointKeys = map[string]bool{"endpoint": true, "access_key": true, "secret_key": true}
+
+// endpointConfig reads the s3 settings of one endpoint
+type endpointConfig struct {
+	config *confer.Config
+	name   string
+}
+
+// newEndpointConfig returns the settings of the named endpoint, an unknown name is a ConfigError
+func newEndpointConfig(config *confer.Config, name string) (*endpointConfig, error) {
+	if name != DefaultEndpoint {
+		if _, ok := config.GetStringMap("s3.endpoints")[name]; !ok {
+			return nil, &ConfigError{Err: fmt.Errorf("s3.endpoints has no endpoint [%s]", name)}
+		}
+	}
+	return &endpointConfig{config: config, name: name}, nil
+}
+
+// key returns the config key of setting: s3.endpoints.<name>.<setting> if it's set, s3.<setting> otherwise
+func (e *endpointConfig) key(setting string) string {
+	if e.name == DefaultEndpoint {
+		return "s3." + setting
+	}
+	key := "s3.endpoints." + e.name + "." + setting
+	if endpointKeys[setting] || e.config.IsSet(key) {
+		return key
+	}
+	return "s3." + setting
+}
+
+func (e *endpointConfig) getString(setting string) string {
+	return e.config.GetString(e.key(setting))
+}
+
+func (e *endpointConfig) getBool(setting string) bool {
+	return e.config.GetBool(e.key(setting))
+}
+
+// bucket returns the bucket of the endpoint, s3.demo_bucket_name for DefaultEndpoint
+func (e *endpointConfig) bucket() string {
+	if e.name == DefaultEndpoint {
+		return e.config.GetString("s3.demo_bucket_name")
+	}
+	return e.config.GetString("s3.endpoints." + e.name + ".bucket")
+}
+
+// EndpointNames returns the names of the configured endpoints, sorted: DefaultEndpoint
+// if s3.endpoint is set, then those under s3.endpoints
+func EndpointNames(config *confer.Config) []string {
+	var names []string
+	for name := range config.GetStringMap("s3.endpoints") {
+		if name != DefaultEndpoint {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(config.GetString("s3.endpoint")) > 0 {
+		names = append([]string{DefaultEndpoint}, names...)
+	}
+	return names
+}
+
+// EndpointBucket returns the bucket configured for the named endpoint
+func EndpointBucket(config *confer.Config, name string) (string, error) {
+	e, err := newEndpointConfig(config, name)
+	if err != nil {
+		return "", err
+	}
+	return e.bucket(), nil
+}
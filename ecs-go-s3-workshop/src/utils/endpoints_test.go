@@ -0,0 +1,90 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jacobstr/confer"
+	. "gopkg.in/check.v1"
+)
+
+type EndpointsSuite struct {
+	config *confer.Config
+}
+
+var _ = Suite(&EndpointsSuite{})
+
+const endpointsYAML = `
+s3:
+  endpoint: https://ecs1.example.com
+  access_key: key1
+  secret_key: secret1
+  region: us-east-1
+  namespace: ns1
+  demo_bucket_name: bucket1
+  endpoints:
+    site2:
+      endpoint: https://ecs2.example.com
+      access_key: key2
+      secret_key: secret2
+      bucket: bucket2
+      namespace: ns2
+    site3:
+      endpoint: https://ecs3.example.com
+      bucket: bucket3
+`
+
+func (s *EndpointsSuite) SetUpTest(c *C) {
+	path := filepath.Join(c.MkDir(), "config.yaml")
+	c.Assert(ioutil.WriteFile(path, []byte(endpointsYAML), 0644), IsNil)
+	config, err := readConfig(path)
+	c.Assert(err, IsNil)
+	s.config = config
+}
+
+func (s *EndpointsSuite) TestEndpointNames(c *C) {
+	c.Check(EndpointNames(s.config), DeepEquals, []string{DefaultEndpoint, "site2", "site3"})
+}
+
+func (s *EndpointsSuite) TestEndpointBucket(c *C) {
+	for name, bucket := range map[string]string{DefaultEndpoint: "bucket1", "site2": "bucket2", "site3": "bucket3"} {
+		actual, err := EndpointBucket(s.config, name)
+		c.Assert(err, IsNil)
+		c.Check(actual, Equals, bucket)
+	}
+}
+
+func (s *EndpointsSuite) TestEndpointSettingsFallBack(c *C) {
+	site2, err := newEndpointConfig(s.config, "site2")
+	c.Assert(err, IsNil)
+	c.Check(site2.getString("endpoint"), Equals, "https://ecs2.example.com")
+	c.Check(site2.getString("namespace"), Equals, "ns2")
+	c.Check(site2.getString("region"), Equals, "us-east-1")
+
+	// Credentials are never taken from the default endpoint
+	site3, err := newEndpointConfig(s.config, "site3")
+	c.Assert(err, IsNil)
+	c.Check(site3.getString("access_key"), Equals, "")
+	c.Check(site3.getString("namespace"), Equals, "ns1")
+}
+
+func (s *EndpointsSuite) TestUnknownEndpoint(c *C) {
+	_, err := GetS3ClientFor(s.config, "site4")
+	c.Check(err, ErrorMatches, "config: s3.endpoints has no endpoint \\[site4\\]")
+	c.Check(err, FitsTypeOf, &ConfigError{})
+}
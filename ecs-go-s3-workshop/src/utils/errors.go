@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
 )
 
 /*
@@ -20,11 +26,173 @@ import (
  * permissions and limitations under the License.
  */
 
-// Check errors
+// AWS error codes the samples branch on that have no constant in the s3 package
+const (
+	ErrCodeNotFound                        = "NotFound"
+	ErrCodeNoSuchVersion                   = "NoSuchVersion"
+	ErrCodeAccessDenied                    = "AccessDenied"
+	ErrCodeNoSuchBucketPolicy              = "NoSuchBucketPolicy"
+	ErrCodeNoSuchLifecycleConfiguration    = "NoSuchLifecycleConfiguration"
+	ErrCodeNoSuchObjectLockConfiguration   = "NoSuchObjectLockConfiguration"
+	ErrCodeRestoreAlreadyInProgress        = "RestoreAlreadyInProgress"
+	ErrCodePreconditionFailed              = "PreconditionFailed"
+	ErrCodeNotModified                     = "NotModified"
+	ErrCodeSlowDown                        = "SlowDown"
+	ErrCodeInternalError                   = "InternalError"
+	ErrCodeInvalidRange                    = "InvalidRange"
+	ErrCodeRequestError                    = "RequestError"
+	ErrCodeReplicationNotFound             = "ReplicationConfigurationNotFoundError"
+	ErrCodeOwnershipControlsNotFound       = "OwnershipControlsNotFoundError"
+	ErrCodeNotImplemented                  = "NotImplemented"
+	ErrCodeObjectLockConfigurationNotFound = "ObjectLockConfigurationNotFoundError"
+	ErrCodeAccessControlListNotSupported   = "AccessControlListNotSupported"
+)
+
+// Sentinels of the failure classes, errors.Is matches them against the typed errors below
+var (
+	ErrConfig     = errors.New("config error")
+	ErrConnection = errors.New("connection error")
+	ErrNotFound   = errors.New("not found")
+)
+
+// ConfigError is a config file or setting that is missing or invalid
+type ConfigError struct {
+	// Path is the config file, empty for a setting of the loaded config
+	Path string
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	if len(e.Path) > 0 {
+		return fmt.Sprintf("config [%s]: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("config: %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrConfig
+func (e *ConfigError) Is(target error) bool { return target == ErrConfig }
+
+// ConnectionError is a request that didn't get a response from the server
+type ConnectionError struct {
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("connection failed: %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrConnection
+func (e *ConnectionError) Is(target error) bool { return target == ErrConnection }
+
+// NotFoundError is a bucket, object or version that doesn't exist
+type NotFoundError struct {
+	Bucket string
+	// Key is empty when the bucket itself wasn't found
+	Key string
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	if len(e.Key) > 0 {
+		return fmt.Sprintf("object [%s/%s] not found: %v", e.Bucket, e.Key, e.Err)
+	}
+	return fmt.Sprintf("bucket [%s] not found: %v", e.Bucket, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrNotFound
+func (e *NotFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// ClassifyError wraps an error of a request on bucket/key in the typed error of
+// its failure class, errors of no particular class are returned unchanged
+func ClassifyError(err error, bucket, key string) error {
+	aerr, ok := awsError(err)
+	if !ok {
+		if _, ok := err.(net.Error); ok {
+			return &ConnectionError{Err: err}
+		}
+		return err
+	}
+
+	switch aerr.Code() {
+	case s3.ErrCodeNoSuchBucket:
+		return &NotFoundError{Bucket: bucket, Err: err}
+	case s3.ErrCodeNoSuchKey, ErrCodeNotFound, ErrCodeNoSuchVersion:
+		return &NotFoundError{Bucket: bucket, Key: key, Err: err}
+	case ErrCodeRequestError:
+		return &ConnectionError{Err: err}
+	}
+	return err
+}
+
+// Check errors, a non-nil err is printed to stderr and exits with status 1 so
+// that shell pipelines and scripts see the failure
 func Check(err error) {
 	if err == nil {
 		return
 	}
-	fmt.Println(err.Error())
-	os.Exit(0)
+	switch err.(type) {
+	case *ConfigError:
+		fmt.Fprintf(os.Stderr, "ConfigError: %v\n", err)
+	case *ConnectionError:
+		fmt.Fprintf(os.Stderr, "ConnectionError: %v\n", err)
+	case *NotFoundError:
+		fmt.Fprintf(os.Stderr, "NotFoundError: %v\n", err)
+	default:
+		fmt.Fprintln(os.Stderr, err.Error())
+	}
+	os.Exit(1)
+}
+
+// AWSErrCode returns the AWS error code of err, or empty if err isn't an AWS error
+func AWSErrCode(err error) string {
+	if aerr, ok := awsError(err); ok {
+		return aerr.Code()
+	}
+	return ""
+}
+
+// IsAWSErrCode reports whether err is an AWS error with code
+func IsAWSErrCode(err error, code string) bool {
+	aerr, ok := awsError(err)
+	return ok && aerr.Code() == code
+}
+
+// IsNotSupported reports whether err says the endpoint doesn't implement the API
+func IsNotSupported(err error) bool {
+	return awsStatusCode(err) == http.StatusNotImplemented || IsAWSErrCode(err, ErrCodeNotImplemented)
+}
+
+// awsError returns the AWS error err is or wraps
+func awsError(err error) (awserr.Error, bool) {
+	for err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			return aerr, true
+		}
+		wrapper, ok := err.(interface {
+			Unwrap() error
+		})
+		if !ok {
+			break
+		}
+		err = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// awsStatusCode returns the HTTP status code of the AWS error err is or wraps, or 0
+func awsStatusCode(err error) int {
+	aerr, _ := awsError(err)
+	if reqErr, ok := aerr.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode()
+	}
+	return 0
 }
@@ -0,0 +1,96 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+type ErrorsSuite struct{}
+
+var _ = Suite(&ErrorsSuite{})
+
+func (s *ErrorsSuite) TestAWSErrCode(c *C) {
+	err := awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+	c.Check(AWSErrCode(err), Equals, s3.ErrCodeNoSuchKey)
+}
+
+func (s *ErrorsSuite) TestAWSErrCodeRequestFailure(c *C) {
+	err := awserr.NewRequestFailure(awserr.New(ErrCodeNotFound, "Not Found", nil), 404, "req-1")
+	c.Check(AWSErrCode(err), Equals, ErrCodeNotFound)
+}
+
+func (s *ErrorsSuite) TestAWSErrCodeNonAWSError(c *C) {
+	c.Check(AWSErrCode(errors.New("boom")), Equals, "")
+	c.Check(AWSErrCode(nil), Equals, "")
+}
+
+func (s *ErrorsSuite) TestIsAWSErrCode(c *C) {
+	err := awserr.New(ErrCodeAccessDenied, "Access Denied", nil)
+	c.Check(IsAWSErrCode(err, ErrCodeAccessDenied), Equals, true)
+	c.Check(IsAWSErrCode(err, s3.ErrCodeNoSuchKey), Equals, false)
+	c.Check(IsAWSErrCode(nil, ""), Equals, false)
+	c.Check(IsAWSErrCode(errors.New("boom"), ""), Equals, false)
+}
+
+func (s *ErrorsSuite) TestClassifyErrorNotFound(c *C) {
+	err := ClassifyError(awserr.NewRequestFailure(awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil), 404, "req-1"), "bucket", "key")
+	notFound, ok := err.(*NotFoundError)
+	c.Assert(ok, Equals, true)
+	c.Check(notFound.Is(ErrNotFound), Equals, true)
+	c.Check(notFound.Is(ErrConfig), Equals, false)
+	// The request failure adds a status code and request id line
+	c.Check(err, ErrorMatches, `(?s)object \[bucket/key\] not found: NoSuchKey: .*`)
+
+	// The AWS error stays reachable through the wrapper
+	c.Check(AWSErrCode(err), Equals, s3.ErrCodeNoSuchKey)
+	c.Check(IsPreconditionFailed(err), Equals, false)
+
+	err = ClassifyError(awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist.", nil), "bucket", "key")
+	c.Check(err, ErrorMatches, `bucket \[bucket\] not found: .*`)
+}
+
+func (s *ErrorsSuite) TestClassifyErrorConnection(c *C) {
+	err := ClassifyError(awserr.New(ErrCodeRequestError, "send request failed", errors.New("connection refused")), "bucket", "key")
+	conn, ok := err.(*ConnectionError)
+	c.Assert(ok, Equals, true)
+	c.Check(conn.Is(ErrConnection), Equals, true)
+	c.Check(conn.Unwrap(), NotNil)
+}
+
+func (s *ErrorsSuite) TestClassifyErrorUnchanged(c *C) {
+	err := awserr.New(ErrCodeAccessDenied, "Access Denied", nil)
+	c.Check(ClassifyError(err, "bucket", "key"), Equals, err)
+	c.Check(ClassifyError(nil, "bucket", "key"), IsNil)
+}
+
+func (s *ErrorsSuite) TestConfigError(c *C) {
+	cause := errors.New("no such file or directory")
+	err := &ConfigError{Path: "config.yaml", Err: cause}
+	c.Check(err, ErrorMatches, `config \[config.yaml\]: no such file or directory`)
+	c.Check(err.Is(ErrConfig), Equals, true)
+	c.Check(err.Unwrap(), Equals, cause)
+}
+
+func (s *ErrorsSuite) TestIsNotSupported(c *C) {
+	c.Check(IsNotSupported(awserr.NewRequestFailure(awserr.New("Unknown", "Not Implemented", nil), 501, "req-1")), Equals, true)
+	c.Check(IsNotSupported(awserr.New(ErrCodeNotImplemented, "A header you provided implies functionality that is not implemented", nil)), Equals, true)
+	c.Check(IsNotSupported(awserr.New(ErrCodeAccessDenied, "Access Denied", nil)), Equals, false)
+}
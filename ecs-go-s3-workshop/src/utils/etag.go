@@ -0,0 +1,82 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultPartSize is the part size the samples upload multipart objects with
+const DefaultPartSize = 5 << 20 // 5MB
+
+// ComputeMultipartETag returns the ETag S3 gives an object uploaded from r in parts of
+// partSize: the hex MD5 of the concatenated MD5s of the parts, followed by -<parts>
+func ComputeMultipartETag(r io.Reader, partSize int64) (string, error) {
+	if partSize <= 0 {
+		return "", fmt.Errorf("part size must be positive, not %d", partSize)
+	}
+
+	sums := md5.New()
+	parts := 0
+	for {
+		part := md5.New()
+		n, err := io.CopyN(part, r, partSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		// An empty object is still uploaded as one empty part
+		if n == 0 && parts > 0 {
+			break
+		}
+		sums.Write(part.Sum(nil))
+		parts++
+		if err == io.EOF {
+			break
+		}
+	}
+	return fmt.Sprintf("%x-%d", sums.Sum(nil), parts), nil
+}
+
+// MultipartETagParts returns the number of parts of a multipart ETag such as
+// "9a6dbec798b1bfe66cc7659d2bb41720-2", ok is false for any other ETag
+func MultipartETagParts(etag string) (parts int, ok bool) {
+	etag = strings.Trim(etag, "\"")
+	i := strings.LastIndex(etag, "-")
+	if i < 0 {
+		return 0, false
+	}
+	parts, err := strconv.Atoi(etag[i+1:])
+	if err != nil || parts < 1 {
+		return 0, false
+	}
+	return parts, true
+}
+
+// VerifyMultipartETag checks the content of r has the multipart etag when uploaded in parts of partSize
+func VerifyMultipartETag(r io.Reader, partSize int64, etag string) error {
+	computed, err := ComputeMultipartETag(r, partSize)
+	if err != nil {
+		return err
+	}
+	if expected := strings.Trim(etag, "\""); computed != expected {
+		return fmt.Errorf("multipart ETag mismatch: computed [%s] with %d byte parts, server returned [%s]", computed, partSize, expected)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type ETagSuite struct{}
+
+var _ = Suite(&ETagSuite{})
+
+// Expected ETags are those S3 returns for the content uploaded in parts of the given size
+func (s *ETagSuite) TestComputeMultipartETag(c *C) {
+	large := make([]byte, 12<<20)
+	for i := range large {
+		large[i] = byte(i % 251)
+	}
+
+	for _, t := range []struct {
+		content  []byte
+		partSize int64
+		etag     string
+	}{
+		{[]byte("hello multipart world"), 5, "f1be0233af1cf0bf73e6e7c1a6c5625e-5"},
+		{[]byte("0123456789"), 5, "9a6dbec798b1bfe66cc7659d2bb41720-2"},
+		{large, DefaultPartSize, "7df28755d1a6cc911533a3b50170cf7d-3"},
+	} {
+		etag, err := ComputeMultipartETag(bytes.NewReader(t.content), t.partSize)
+		c.Assert(err, IsNil)
+		c.Check(etag, Equals, t.etag)
+	}
+}
+
+func (s *ETagSuite) TestComputeMultipartETagInvalidPartSize(c *C) {
+	_, err := ComputeMultipartETag(strings.NewReader("content"), 0)
+	c.Check(err, ErrorMatches, "part size must be positive, not 0")
+}
+
+func (s *ETagSuite) TestMultipartETagParts(c *C) {
+	parts, ok := MultipartETagParts(`"9a6dbec798b1bfe66cc7659d2bb41720-2"`)
+	c.Check(ok, Equals, true)
+	c.Check(parts, Equals, 2)
+
+	for _, etag := range []string{`"9a6dbec798b1bfe66cc7659d2bb41720"`, "abc-", "abc-0", "abc-x"} {
+		_, ok = MultipartETagParts(etag)
+		c.Check(ok, Equals, false, Commentf("%s", etag))
+	}
+}
+
+func (s *ETagSuite) TestVerifyMultipartETag(c *C) {
+	c.Check(VerifyMultipartETag(strings.NewReader("0123456789"), 5, `"9a6dbec798b1bfe66cc7659d2bb41720-2"`), IsNil)
+	c.Check(VerifyMultipartETag(strings.NewReader("0123456789"), 4, `"9a6dbec798b1bfe66cc7659d2bb41720-2"`), ErrorMatches,
+		"multipart ETag mismatch: computed \\[.*-3\\] with 4 byte parts, server returned \\[9a6dbec798b1bfe66cc7659d2bb41720-2\\]")
+}
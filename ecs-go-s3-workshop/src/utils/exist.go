@@ -0,0 +1,91 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ObjectsExist heads keys of bucket with up to concurrency requests at once and
+// reports which exist. A 404 means the key doesn't exist; any other error stops
+// heading further keys and is returned, classified like ClassifyError does.
+func ObjectsExist(s3client S3API, bucket string, keys []string, concurrency int) (map[string]bool, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// A HEAD of a key in a missing bucket is a bare 404 like that of a missing
+	// key, so check the bucket once up front instead of reporting every key absent
+	_, err := s3client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return nil, ClassifyError(err, bucket, "")
+	}
+
+	var (
+		mu       sync.Mutex
+		exists   = make(map[string]bool, len(keys))
+		firstErr error
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	keyCh := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keyCh {
+				_, err := s3client.HeadObject(&s3.HeadObjectInput{
+					Bucket: aws.String(bucket),
+					Key:    aws.String(key),
+				})
+				notFound := awsStatusCode(err) == http.StatusNotFound
+
+				mu.Lock()
+				switch {
+				case err == nil || notFound:
+					exists[key] = err == nil
+				case firstErr == nil:
+					firstErr = ClassifyError(err, bucket, key)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Stop feeding keys once a head failed, the ones in flight still complete
+	for _, key := range keys {
+		if failed() {
+			break
+		}
+		keyCh <- key
+	}
+	close(keyCh)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return exists, nil
+}
@@ -0,0 +1,84 @@
+package utils_test
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"utils"
+	"utils/fake"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+type ExistSuite struct {
+	s3client *fake.S3
+}
+
+var _ = Suite(&ExistSuite{})
+
+func (s *ExistSuite) SetUpTest(c *C) {
+	s.s3client = fake.NewS3("bucket")
+}
+
+func (s *ExistSuite) put(c *C, key string) {
+	_, err := s.s3client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(key),
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *ExistSuite) TestObjectsExist(c *C) {
+	var keys []string
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%03d", i)
+		if i%3 == 0 {
+			s.put(c, key)
+		}
+		keys = append(keys, key)
+	}
+
+	// Run with -race to check the workers fill the map safely
+	exists, err := utils.ObjectsExist(s.s3client, "bucket", keys, 8)
+	c.Assert(err, IsNil)
+	c.Assert(exists, HasLen, len(keys))
+	for i, key := range keys {
+		c.Check(exists[key], Equals, i%3 == 0, Commentf("%s", key))
+	}
+}
+
+func (s *ExistSuite) TestObjectsExistSequential(c *C) {
+	s.put(c, "present")
+	exists, err := utils.ObjectsExist(s.s3client, "bucket", []string{"present", "missing"}, 0)
+	c.Assert(err, IsNil)
+	c.Check(exists, DeepEquals, map[string]bool{"present": true, "missing": false})
+}
+
+func (s *ExistSuite) TestObjectsExistNoKeys(c *C) {
+	exists, err := utils.ObjectsExist(s.s3client, "bucket", nil, 4)
+	c.Assert(err, IsNil)
+	c.Check(exists, HasLen, 0)
+}
+
+func (s *ExistSuite) TestObjectsExistMissingBucket(c *C) {
+	_, err := utils.ObjectsExist(s.s3client, "missing", []string{"a", "b", "c"}, 2)
+	c.Check(err, FitsTypeOf, &utils.NotFoundError{})
+	c.Check(err, ErrorMatches, "(?s)bucket \\[missing\\] not found: NotFound: .*")
+}
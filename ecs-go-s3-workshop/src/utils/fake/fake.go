@@ -0,0 +1,462 @@
+// Package fake provides an in-memory S3 so command logic can be tested without an ECS endpoint
+package fake
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// MaxKeys is the page size of ListObjectsV2 when the input doesn't set one
+const MaxKeys = 1000
+
+// object is a stored object
+type object struct {
+	data               []byte
+	etag               string
+	contentType        string
+	cacheControl       string
+	contentDisposition string
+	contentEncoding    string
+	contentLanguage    string
+	expires            string
+	redirectLocation   string
+	storageClass       string
+	metadata           map[string]*string
+	lastModified       time.Time
+}
+
+// S3 is an in-memory, unversioned S3API. Only buckets passed to NewS3 exist.
+type S3 struct {
+	mu           sync.Mutex
+	buckets      map[string]map[string]*object
+	deleteErrors map[string][]string
+}
+
+var _ utils.S3API = (*S3)(nil)
+
+// NewS3 returns an S3 with the given empty buckets
+func NewS3(buckets ...string) *S3 {
+	f := &S3{buckets: map[string]map[string]*object{}, deleteErrors: map[string][]string{}}
+	for _, bucket := range buckets {
+		f.buckets[bucket] = map[string]*object{}
+	}
+	return f
+}
+
+// Keys returns the sorted keys stored in bucket
+func (f *S3) Keys(bucket string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.buckets[bucket]))
+	for key := range f.buckets[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FailDeletes makes the next DeleteObjects of key fail with codes, one per call
+func (f *S3) FailDeletes(key string, codes ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleteErrors[key] = append(f.deleteErrors[key], codes...)
+}
+
+// bucket returns the objects of name, the caller must hold f.mu
+func (f *S3) bucket(name *string) (map[string]*object, error) {
+	objects, ok := f.buckets[aws.StringValue(name)]
+	if !ok {
+		return nil, requestFailure(s3.ErrCodeNoSuchBucket, http.StatusNotFound)
+	}
+	return objects, nil
+}
+
+// lookup returns the object key of bucket, the caller must hold f.mu
+func (f *S3) lookup(bucket, key *string) (*object, error) {
+	objects, err := f.bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := objects[aws.StringValue(key)]
+	if !ok {
+		return nil, requestFailure(s3.ErrCodeNoSuchKey, http.StatusNotFound)
+	}
+	return obj, nil
+}
+
+// HeadBucket succeeds for buckets passed to NewS3, like HeadObject a missing
+// bucket is a bare 404 NotFound
+func (f *S3) HeadBucket(input *s3.HeadBucketInput) (*s3.HeadBucketOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.bucket(input.Bucket); err != nil {
+		return nil, requestFailure(utils.ErrCodeNotFound, http.StatusNotFound)
+	}
+	return &s3.HeadBucketOutput{}, nil
+}
+
+// PutObject stores the body of input
+func (f *S3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return f.PutObjectWithContext(aws.BackgroundContext(), input)
+}
+
+// PutObjectWithContext is PutObject honouring the If-Match and If-None-Match
+// headers that opts set when the request is built, see utils.Conditions.PutOption
+func (f *S3) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	header := builtHeader(opts)
+	existing := objects[aws.StringValue(input.Key)]
+	if err := checkPreconditions(existing, headerValue(header, "If-Match"), headerValue(header, "If-None-Match"), false); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if input.Body != nil {
+		if data, err = ioutil.ReadAll(input.Body); err != nil {
+			return nil, err
+		}
+	}
+	sum := md5.Sum(data)
+	obj := &object{
+		data:               data,
+		etag:               "\"" + hex.EncodeToString(sum[:]) + "\"",
+		contentType:        aws.StringValue(input.ContentType),
+		cacheControl:       aws.StringValue(input.CacheControl),
+		contentDisposition: aws.StringValue(input.ContentDisposition),
+		contentEncoding:    aws.StringValue(input.ContentEncoding),
+		contentLanguage:    aws.StringValue(input.ContentLanguage),
+		expires:            formatExpires(input.Expires),
+		redirectLocation:   aws.StringValue(input.WebsiteRedirectLocation),
+		storageClass:       aws.StringValue(input.StorageClass),
+		metadata:           input.Metadata,
+		lastModified:       time.Now().UTC(),
+	}
+	objects[aws.StringValue(input.Key)] = obj
+	return &s3.PutObjectOutput{ETag: aws.String(obj.etag)}, nil
+}
+
+// builtHeader returns the HTTP headers opts set on a request when it's built
+func builtHeader(opts []request.Option) http.Header {
+	r := &request.Request{HTTPRequest: &http.Request{Header: http.Header{}}}
+	r.ApplyOptions(opts...)
+	r.Handlers.Build.Run(r)
+	return r.HTTPRequest.Header
+}
+
+// headerValue returns the value of name in header, nil if it isn't set
+func headerValue(header http.Header, name string) *string {
+	if v := header.Get(name); len(v) > 0 {
+		return aws.String(v)
+	}
+	return nil
+}
+
+// GetObject returns the content of an object, honouring If-Match, If-None-Match and Range
+func (f *S3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, err := f.lookup(input.Bucket, input.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPreconditions(obj, input.IfMatch, input.IfNoneMatch, true); err != nil {
+		return nil, err
+	}
+
+	resp := &s3.GetObjectOutput{
+		ContentType:  aws.String(obj.contentType),
+		ETag:         aws.String(obj.etag),
+		LastModified: aws.Time(obj.lastModified),
+		Metadata:     obj.metadata,
+	}
+	data := obj.data
+	if input.Range != nil {
+		start, end, err := parseRange(aws.StringValue(input.Range), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		data = data[start : end+1]
+		resp.ContentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, len(obj.data)))
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = aws.Int64(int64(len(data)))
+	return resp, nil
+}
+
+// HeadObject returns the metadata of an object, a missing one is NotFound like on a real HEAD
+func (f *S3) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// A HEAD response has no body to carry an error code, so a missing bucket
+	// looks like a missing key: a bare 404 NotFound
+	obj, err := f.lookup(input.Bucket, input.Key)
+	if utils.IsAWSErrCode(err, s3.ErrCodeNoSuchKey) || utils.IsAWSErrCode(err, s3.ErrCodeNoSuchBucket) {
+		return nil, requestFailure(utils.ErrCodeNotFound, http.StatusNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &s3.HeadObjectOutput{
+		ContentLength:           aws.Int64(int64(len(obj.data))),
+		ContentType:             aws.String(obj.contentType),
+		CacheControl:            aws.String(obj.cacheControl),
+		ContentDisposition:      aws.String(obj.contentDisposition),
+		ContentEncoding:         optional(obj.contentEncoding),
+		ContentLanguage:         optional(obj.contentLanguage),
+		Expires:                 optional(obj.expires),
+		WebsiteRedirectLocation: optional(obj.redirectLocation),
+		ETag:                    aws.String(obj.etag),
+		LastModified:            aws.Time(obj.lastModified),
+		Metadata:                obj.metadata,
+	}
+	// Like S3, STANDARD isn't reported
+	if len(obj.storageClass) > 0 {
+		resp.StorageClass = aws.String(obj.storageClass)
+	}
+	return resp, nil
+}
+
+// CopyObject copies an object within or between buckets, keeping its metadata
+// unless MetadataDirective is REPLACE. Like S3, copying an object onto itself
+// needs REPLACE or another storage class, and honours CopySourceIfMatch.
+func (f *S3) CopyObject(input *s3.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copySource, err := url.Parse(aws.StringValue(input.CopySource))
+	if err != nil {
+		return nil, err
+	}
+	source := strings.SplitN(strings.TrimPrefix(copySource.Path, "/"), "/", 2)
+	if len(source) != 2 {
+		return nil, requestFailure("InvalidArgument", http.StatusBadRequest)
+	}
+	obj, err := f.lookup(aws.String(source[0]), aws.String(source[1]))
+	if err != nil {
+		return nil, err
+	}
+	objects, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPreconditions(obj, input.CopySourceIfMatch, nil, false); err != nil {
+		return nil, err
+	}
+
+	replace := aws.StringValue(input.MetadataDirective) == s3.MetadataDirectiveReplace
+	storageClass := aws.StringValue(input.StorageClass)
+	self := source[0] == aws.StringValue(input.Bucket) && source[1] == aws.StringValue(input.Key)
+	if self && !replace && storageClass == obj.storageClass {
+		return nil, requestFailure("InvalidRequest", http.StatusBadRequest)
+	}
+
+	copied := *obj
+	copied.storageClass = storageClass
+	copied.lastModified = time.Now().UTC()
+	if replace {
+		copied.contentType = aws.StringValue(input.ContentType)
+		copied.cacheControl = aws.StringValue(input.CacheControl)
+		copied.contentDisposition = aws.StringValue(input.ContentDisposition)
+		copied.contentEncoding = aws.StringValue(input.ContentEncoding)
+		copied.contentLanguage = aws.StringValue(input.ContentLanguage)
+		copied.expires = formatExpires(input.Expires)
+		copied.redirectLocation = aws.StringValue(input.WebsiteRedirectLocation)
+		copied.metadata = input.Metadata
+	}
+	objects[aws.StringValue(input.Key)] = &copied
+	return &s3.CopyObjectOutput{
+		CopyObjectResult: &s3.CopyObjectResult{
+			ETag:         aws.String(copied.etag),
+			LastModified: aws.Time(copied.lastModified),
+		},
+	}, nil
+}
+
+// DeleteObject deletes an object, deleting a missing key succeeds like on S3
+func (f *S3) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if len(aws.StringValue(input.VersionId)) > 0 {
+		// The bucket is unversioned, there are no versions to delete
+		return nil, requestFailure(utils.ErrCodeNoSuchVersion, http.StatusNotFound)
+	}
+	delete(objects, aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// DeleteObjects deletes several objects, only reporting the deleted ones if the
+// request isn't quiet. Keys set up with FailDeletes are reported as errors.
+func (f *S3) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &s3.DeleteObjectsOutput{}
+	for _, id := range input.Delete.Objects {
+		key := aws.StringValue(id.Key)
+		if codes := f.deleteErrors[key]; len(codes) > 0 {
+			f.deleteErrors[key] = codes[1:]
+			resp.Errors = append(resp.Errors, &s3.Error{Key: id.Key, VersionId: id.VersionId, Code: aws.String(codes[0]), Message: aws.String(codes[0])})
+			continue
+		}
+		delete(objects, key)
+		if !aws.BoolValue(input.Delete.Quiet) {
+			resp.Deleted = append(resp.Deleted, &s3.DeletedObject{Key: id.Key})
+		}
+	}
+	return resp, nil
+}
+
+// ListObjectsV2 lists one page of objects in key order. The continuation token is the last key returned.
+func (f *S3) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, err := f.bucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := aws.StringValue(input.Prefix)
+	after := aws.StringValue(input.StartAfter)
+	if input.ContinuationToken != nil {
+		after = aws.StringValue(input.ContinuationToken)
+	}
+	maxKeys := int(aws.Int64Value(input.MaxKeys))
+	if maxKeys <= 0 {
+		maxKeys = MaxKeys
+	}
+
+	keys := []string{}
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	resp := &s3.ListObjectsV2Output{Prefix: input.Prefix, IsTruncated: aws.Bool(len(keys) > maxKeys)}
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		resp.NextContinuationToken = aws.String(keys[maxKeys-1])
+	}
+	for _, key := range keys {
+		obj := objects[key]
+		resp.Contents = append(resp.Contents, &s3.Object{
+			Key:          aws.String(key),
+			Size:         aws.Int64(int64(len(obj.data))),
+			ETag:         aws.String(obj.etag),
+			LastModified: aws.Time(obj.lastModified),
+			StorageClass: aws.String(s3.ObjectStorageClassStandard),
+		})
+	}
+	resp.KeyCount = aws.Int64(int64(len(resp.Contents)))
+	return resp, nil
+}
+
+// ListObjectsV2Pages calls fn with each page of ListObjectsV2 until fn returns false
+func (f *S3) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	params := *input
+	for {
+		page, err := f.ListObjectsV2(&params)
+		if err != nil {
+			return err
+		}
+		lastPage := !aws.BoolValue(page.IsTruncated)
+		if !fn(page, lastPage) || lastPage {
+			return nil
+		}
+		params.ContinuationToken = page.NextContinuationToken
+	}
+}
+
+// parseRange parses a single bytes=start-[end] range of an object of size bytes,
+// an end beyond the object is clamped like on S3
+func parseRange(header string, size int64) (int64, int64, error) {
+	var start, end int64
+	if n, _ := fmt.Sscanf(header, "bytes=%d-%d", &start, &end); n == 0 {
+		return 0, 0, requestFailure(utils.ErrCodeInvalidRange, http.StatusRequestedRangeNotSatisfiable)
+	} else if n == 1 || end >= size {
+		end = size - 1
+	}
+	if start >= size || start > end {
+		return 0, 0, requestFailure(utils.ErrCodeInvalidRange, http.StatusRequestedRangeNotSatisfiable)
+	}
+	return start, end, nil
+}
+
+// checkPreconditions evaluates If-Match and If-None-Match against obj, which may
+// be nil. A matching If-None-Match is 304 Not Modified on a read, 412 on a write.
+func checkPreconditions(obj *object, ifMatch, ifNoneMatch *string, read bool) error {
+	if ifMatch != nil && (obj == nil || (*ifMatch != "*" && *ifMatch != obj.etag)) {
+		return requestFailure(utils.ErrCodePreconditionFailed, http.StatusPreconditionFailed)
+	}
+	if ifNoneMatch != nil && obj != nil && (*ifNoneMatch == "*" || *ifNoneMatch == obj.etag) {
+		if read {
+			return requestFailure(utils.ErrCodeNotModified, http.StatusNotModified)
+		}
+		return requestFailure(utils.ErrCodePreconditionFailed, http.StatusPreconditionFailed)
+	}
+	return nil
+}
+
+// optional returns nil for an empty header value, like a response without the header
+func optional(v string) *string {
+	if len(v) == 0 {
+		return nil
+	}
+	return aws.String(v)
+}
+
+// formatExpires returns the Expires header value of t, empty if t is nil
+func formatExpires(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(http.TimeFormat)
+}
+
+// requestFailure returns an error shaped like the ones the SDK returns for code
+func requestFailure(code string, statusCode int) error {
+	return awserr.NewRequestFailure(awserr.New(code, code, nil), statusCode, "fake")
+}
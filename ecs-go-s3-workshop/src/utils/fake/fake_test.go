@@ -0,0 +1,126 @@
+package fake
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"ops"
+	"strings"
+	"testing"
+	"utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+// Launch gocheck tests
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type FakeSuite struct {
+	s3client *S3
+}
+
+var _ = Suite(&FakeSuite{})
+
+func (s *FakeSuite) SetUpTest(c *C) {
+	s.s3client = NewS3("bucket")
+}
+
+func (s *FakeSuite) TestPutGetObject(c *C) {
+	_, err := ops.PutObject(s.s3client, "bucket", "key.txt", strings.NewReader("hello"), nil)
+	c.Assert(err, IsNil)
+
+	var buf bytes.Buffer
+	resp, err := ops.GetObject(s.s3client, "bucket", "key.txt", &buf, nil)
+	c.Assert(err, IsNil)
+	c.Check(buf.String(), Equals, "hello")
+	c.Check(aws.StringValue(resp.ETag), Equals, "\"5d41402abc4b2a76b9719d911017c592\"")
+	c.Check(aws.StringValue(resp.ContentType), Equals, "text/plain; charset=utf-8")
+}
+
+func (s *FakeSuite) TestMissing(c *C) {
+	_, err := ops.GetObject(s.s3client, "bucket", "missing", &bytes.Buffer{}, nil)
+	c.Check(utils.IsAWSErrCode(err, s3.ErrCodeNoSuchKey), Equals, true)
+
+	_, err = ops.StatObject(s.s3client, "bucket", "missing")
+	c.Check(utils.IsAWSErrCode(err, utils.ErrCodeNotFound), Equals, true)
+
+	_, err = ops.DeleteObject(s.s3client, "other", "key", "")
+	c.Check(utils.IsAWSErrCode(err, s3.ErrCodeNoSuchBucket), Equals, true)
+}
+
+func (s *FakeSuite) TestConditions(c *C) {
+	resp, err := ops.PutObject(s.s3client, "bucket", "key", strings.NewReader("v1"), nil)
+	c.Assert(err, IsNil)
+
+	_, err = ops.PutObject(s.s3client, "bucket", "key", strings.NewReader("v2"),
+		&ops.PutOptions{Conditions: &utils.Conditions{IfNoneMatch: "*"}})
+	c.Check(utils.IsPreconditionFailed(err), Equals, true)
+
+	_, err = ops.GetObject(s.s3client, "bucket", "key", &bytes.Buffer{},
+		&ops.GetOptions{Conditions: &utils.Conditions{IfNoneMatch: aws.StringValue(resp.ETag)}})
+	c.Check(utils.IsNotModified(err), Equals, true)
+
+	_, err = ops.PutObject(s.s3client, "bucket", "key", strings.NewReader("v2"),
+		&ops.PutOptions{Conditions: &utils.Conditions{IfMatch: aws.StringValue(resp.ETag)}})
+	c.Check(err, IsNil)
+}
+
+func (s *FakeSuite) TestCopyObject(c *C) {
+	_, err := ops.PutObject(s.s3client, "bucket", "a b", strings.NewReader("content"), nil)
+	c.Assert(err, IsNil)
+	_, err = ops.CopyObject(s.s3client, "bucket", "a b", "copy")
+	c.Assert(err, IsNil)
+	c.Check(s.s3client.Keys("bucket"), DeepEquals, []string{"a b", "copy"})
+}
+
+func (s *FakeSuite) TestListAndDeleteObjects(c *C) {
+	for i := 0; i < 5; i++ {
+		_, err := ops.PutObject(s.s3client, "bucket", fmt.Sprintf("dir/%d", i), strings.NewReader("x"), nil)
+		c.Assert(err, IsNil)
+	}
+	_, err := ops.PutObject(s.s3client, "bucket", "other", strings.NewReader("x"), nil)
+	c.Assert(err, IsNil)
+
+	// Page through two keys at a time
+	pages := 0
+	keys := []string{}
+	params := &s3.ListObjectsV2Input{Bucket: aws.String("bucket"), Prefix: aws.String("dir/"), MaxKeys: aws.Int64(2)}
+	err = s.s3client.ListObjectsV2Pages(params, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		pages++
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.StringValue(obj.Key))
+		}
+		return true
+	})
+	c.Assert(err, IsNil)
+	c.Check(pages, Equals, 3)
+	c.Check(keys, DeepEquals, []string{"dir/0", "dir/1", "dir/2", "dir/3", "dir/4"})
+
+	objects := []*s3.ObjectIdentifier{}
+	err = ops.ListObjects(s.s3client, "bucket", "dir/", func(obj *s3.Object) {
+		objects = append(objects, &s3.ObjectIdentifier{Key: obj.Key})
+	})
+	c.Assert(err, IsNil)
+	failed, err := ops.DeleteObjects(s.s3client, "bucket", objects, nil)
+	c.Assert(err, IsNil)
+	c.Check(failed, HasLen, 0)
+	c.Check(s.s3client.Keys("bucket"), DeepEquals, []string{"other"})
+}
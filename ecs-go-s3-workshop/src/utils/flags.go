@@ -0,0 +1,47 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+import (
+	"flag"
+)
+
+// Flags shared by all commands
+var (
+	configFlag = flag.String("config", "", "path to config file (overrides $"+ConfigEnvVar+")")
+	dryRunFlag = flag.Bool("dry-run", false, "print what destructive commands would delete or change without doing it")
+	outputFlag = flag.String("output", OutputText, "output format of list/stat commands: "+OutputText+" or "+OutputJSON)
+	forceFlag  = flag.Bool("force", false, "transfer unchanged objects anyway: download despite s3.cache_dir, upload in 16_UploadDir")
+)
+
+// ParseFlags parses the shared command line flags, it's safe to call more than once
+func ParseFlags() {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+}
+
+// DryRun reports whether destructive commands should only print what they would do
+func DryRun() bool {
+	ParseFlags()
+	return *dryRunFlag
+}
+
+// Force reports whether unchanged objects should be transferred anyway, bypassing
+// the download cache and the unchanged check of 16_UploadDir
+func Force() bool {
+	ParseFlags()
+	return *forceFlag
+}
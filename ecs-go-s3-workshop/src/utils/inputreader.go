@@ -3,7 +3,11 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 /*
@@ -24,18 +28,73 @@ import (
 // InputReader is a wrapper of bufio.Reader
 type InputReader struct {
 	*bufio.Reader
+	// out is where prompts are printed
+	out io.Writer
 }
 
-// GetInputStr returns input string
+// GetInputStr returns input string, empty at the end of input
 func (r *InputReader) GetInputStr(msg string) string {
-	fmt.Println(msg)
-	val, _ := r.ReadString('\n')
-	return val[:len(val)-1]
+	val, _ := r.readLine(msg)
+	return val
+}
+
+// readLine prints msg and returns the next line without its line ending, the
+// error is io.EOF when input ended before a line ending
+func (r *InputReader) readLine(msg string) (string, error) {
+	fmt.Fprintln(r.out, msg)
+	val, err := r.ReadString('\n')
+	return strings.TrimRight(val, "\r\n"), err
+}
+
+// GetInputInt returns input parsed as a base 10 integer
+func (r *InputReader) GetInputInt(msg string) (int64, error) {
+	return strconv.ParseInt(r.GetInputStr(msg), 10, 64)
+}
+
+// GetInputDuration returns input parsed as a time.Duration, which also accepts days such as "7d"
+func (r *InputReader) GetInputDuration(msg string) (time.Duration, error) {
+	val := r.GetInputStr(msg)
+	if strings.HasSuffix(val, "d") {
+		days, err := strconv.ParseInt(strings.TrimSuffix(val, "d"), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", val)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(val)
+}
+
+// GetInputBool returns input parsed as yes/no, y/n, true/false or 1/0 in any case
+func (r *InputReader) GetInputBool(msg string) (bool, error) {
+	val := r.GetInputStr(msg)
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "y", "yes", "true", "1":
+		return true, nil
+	case "n", "no", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid yes/no answer %q", val)
+}
+
+// ConfirmAction asks until expected is typed back, an empty line or the end of input cancels
+func (r *InputReader) ConfirmAction(msg, expected string) bool {
+	for {
+		val, err := r.readLine(msg)
+		if val == expected {
+			return true
+		}
+		if val == "" || err != nil {
+			fmt.Fprintln(r.out, "cancelled")
+			return false
+		}
+		fmt.Fprintf(r.out, "[%s] doesn't match [%s], try again or press Enter to cancel\n", val, expected)
+	}
 }
 
 // NewInputReader gets a new InputReader
 func NewInputReader() *InputReader {
 	return &InputReader{
 		Reader: bufio.NewReader(os.Stdin),
+		out:    os.Stdout,
 	}
 }
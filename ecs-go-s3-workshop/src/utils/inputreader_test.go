@@ -0,0 +1,91 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type InputReaderSuite struct{}
+
+var _ = Suite(&InputReaderSuite{})
+
+func newTestReader(input string) *InputReader {
+	return &InputReader{Reader: bufio.NewReader(strings.NewReader(input)), out: ioutil.Discard}
+}
+
+func (s *InputReaderSuite) TestGetInputStr(c *C) {
+	r := newTestReader("first\r\nsecond\nlast")
+	c.Check(r.GetInputStr(""), Equals, "first")
+	c.Check(r.GetInputStr(""), Equals, "second")
+	c.Check(r.GetInputStr(""), Equals, "last")
+	c.Check(r.GetInputStr(""), Equals, "")
+}
+
+func (s *InputReaderSuite) TestConfirmAction(c *C) {
+	for _, t := range []struct {
+		input     string
+		confirmed bool
+		output    string
+	}{
+		{"bucket\n", true, ""},
+		{"buckit\nbucket\n", true, "[buckit] doesn't match [bucket]"},
+		{"\n", false, "cancelled"},
+		{"", false, "cancelled"},
+		{"buck", false, "cancelled"},
+	} {
+		var out bytes.Buffer
+		r := newTestReader(t.input)
+		r.out = &out
+		c.Check(r.ConfirmAction("Type the bucket name:", "bucket"), Equals, t.confirmed, Commentf("%q", t.input))
+		c.Check(strings.Contains(out.String(), t.output), Equals, true, Commentf("%q: %s", t.input, out.String()))
+	}
+}
+
+func (s *InputReaderSuite) TestGetInputDuration(c *C) {
+	d, err := newTestReader("90m\n").GetInputDuration("")
+	c.Assert(err, IsNil)
+	c.Check(d, Equals, 90*time.Minute)
+
+	d, err = newTestReader("7d\n").GetInputDuration("")
+	c.Assert(err, IsNil)
+	c.Check(d, Equals, 7*24*time.Hour)
+}
+
+func (s *InputReaderSuite) TestGetInputDurationInvalid(c *C) {
+	_, err := newTestReader("xd\n").GetInputDuration("")
+	c.Check(err, ErrorMatches, `invalid duration "xd"`)
+
+	_, err = newTestReader("soon\n").GetInputDuration("")
+	c.Check(err, NotNil)
+}
+
+func (s *InputReaderSuite) TestGetInputBool(c *C) {
+	for input, expected := range map[string]bool{"y": true, "Yes": true, "TRUE": true, "1": true, "n": false, "no": false, " false ": false, "0": false} {
+		b, err := newTestReader(input + "\n").GetInputBool("")
+		c.Assert(err, IsNil)
+		c.Check(b, Equals, expected, Commentf("%q", input))
+	}
+
+	_, err := newTestReader("maybe\n").GetInputBool("")
+	c.Check(err, ErrorMatches, `invalid yes/no answer "maybe"`)
+}
@@ -0,0 +1,40 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// secretHeaderRE matches the values of the headers that carry credentials in a logged HTTP request
+var secretHeaderRE = regexp.MustCompile(`(?im)^((?:Authorization|X-Amz-Security-Token):[ \t]*)[^\r\n]*`)
+
+// sdkLogger returns an aws.Logger that writes to logger with the credentials of
+// logged requests redacted, so that s3.debug_http doesn't leak them
+func sdkLogger(logger *log.Logger) aws.Logger {
+	return aws.LoggerFunc(func(args ...interface{}) {
+		logger.Print(redactSecrets(fmt.Sprintln(args...)))
+	})
+}
+
+// redactSecrets replaces the values of the Authorization and X-Amz-Security-Token headers in msg
+func redactSecrets(msg string) string {
+	return secretHeaderRE.ReplaceAllString(msg, "${1}REDACTED")
+}
@@ -0,0 +1,56 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"bytes"
+	"log"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type LoggerSuite struct{}
+
+var _ = Suite(&LoggerSuite{})
+
+func (s *LoggerSuite) TestSDKLoggerRedactsSecrets(c *C) {
+	var buf bytes.Buffer
+	logger := sdkLogger(log.New(&buf, "", 0))
+
+	// As logged by aws.LogDebugWithHTTPBody
+	logger.Log("DEBUG: Request s3/GetObject Details:\n---[ REQUEST POST-SIGN ]-----------------------------\n" +
+		"GET /workshop-bucket/key HTTP/1.1\r\n" +
+		"Host: object.ecstestdrive.com\r\n" +
+		"Authorization: AWS4-HMAC-SHA256 Credential=access/20161228/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=0123456789abcdef\r\n" +
+		"x-amz-security-token: session-token\r\n" +
+		"X-Amz-Date: 20161228T120000Z\r\n" +
+		"\r\n-----------------------------------------------------")
+
+	out := buf.String()
+	c.Check(strings.Contains(out, "Authorization: REDACTED\r\n"), Equals, true)
+	c.Check(strings.Contains(out, "x-amz-security-token: REDACTED\r\n"), Equals, true)
+	c.Check(strings.Contains(out, "Host: object.ecstestdrive.com\r\n"), Equals, true)
+	c.Check(strings.Contains(out, "X-Amz-Date: 20161228T120000Z\r\n"), Equals, true)
+	for _, secret := range []string{"Credential=", "Signature=", "session-token"} {
+		c.Check(strings.Contains(out, secret), Equals, false, Commentf("%s not redacted", secret))
+	}
+}
+
+func (s *LoggerSuite) TestRedactSecretsKeepsOtherLines(c *C) {
+	msg := "DEBUG: Response s3/GetObject Details:\nHTTP/1.1 200 OK\r\nContent-Length: 5\r\n"
+	c.Check(redactSecrets(msg), Equals, msg)
+}
@@ -0,0 +1,69 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Output formats accepted by -output
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// ObjectInfo is the JSON representation of an object
+type ObjectInfo struct {
+	Key                string            `json:"key"`
+	Size               int64             `json:"size"`
+	ETag               string            `json:"etag"`
+	LastModified       string            `json:"lastModified"`
+	ContentType        string            `json:"contentType,omitempty"`
+	StorageClass       string            `json:"storageClass,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	ContentDisposition string            `json:"contentDisposition,omitempty"`
+	CacheControl       string            `json:"cacheControl,omitempty"`
+}
+
+// ObjectListing is the JSON representation of a listing with the stats of its prefix
+type ObjectListing struct {
+	Objects []*ObjectInfo `json:"objects"`
+	Stats   *BucketStats  `json:"stats"`
+}
+
+// NewObjectInfo gets a new ObjectInfo with lastModified formatted as RFC3339
+func NewObjectInfo(key string, size int64, etag string, lastModified time.Time) *ObjectInfo {
+	return &ObjectInfo{
+		Key:          key,
+		Size:         size,
+		ETag:         etag,
+		LastModified: lastModified.UTC().Format(time.RFC3339),
+	}
+}
+
+// IsJSONOutput reports whether -output json was given
+func IsJSONOutput() bool {
+	ParseFlags()
+	return *outputFlag == OutputJSON
+}
+
+// PrintJSON writes v to stdout as indented JSON
+func PrintJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,121 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jacobstr/confer"
+)
+
+const (
+	// DefaultPostExpiry is used when PostConditions.Expiry isn't set
+	DefaultPostExpiry = time.Hour
+	// PostAlgorithm is the signature algorithm of presigned POST policies
+	PostAlgorithm = "AWS4-HMAC-SHA256"
+)
+
+// PostConditions restrict what a presigned POST accepts
+type PostConditions struct {
+	// Expiry is how long the form can be used
+	Expiry time.Duration
+	// MinContentLength and MaxContentLength bound the upload size when MaxContentLength is set
+	MinContentLength int64
+	MaxContentLength int64
+}
+
+// PresignedPost is where and with which form fields a browser uploads an object
+type PresignedPost struct {
+	URL    string
+	Fields map[string]string
+}
+
+// PresignPost signs a POST policy that allows uploading key to the demo bucket
+// without credentials, e.g. from an HTML form in a browser
+func PresignPost(config *confer.Config, key string, conditions *PostConditions) (*PresignedPost, error) {
+	accessKey, secretKey := config.GetString("s3.access_key"), config.GetString("s3.secret_key")
+	if config.GetBool("s3.anonymous") || len(accessKey) == 0 || len(secretKey) == 0 {
+		return nil, &ConfigError{Err: fmt.Errorf("presigned POST needs s3.access_key and s3.secret_key")}
+	}
+	if conditions == nil {
+		conditions = &PostConditions{}
+	}
+	return presignPost(config.GetString("s3.endpoint"), config.GetString("s3.region"), accessKey, secretKey,
+		config.GetString("s3.demo_bucket_name"), key, conditions, time.Now().UTC())
+}
+
+func presignPost(endpoint, region, accessKey, secretKey, bucket, key string, conditions *PostConditions, now time.Time) (*PresignedPost, error) {
+	expiry := conditions.Expiry
+	if expiry <= 0 {
+		expiry = DefaultPostExpiry
+	}
+	date := now.Format("20060102")
+	fields := map[string]string{
+		"key":              key,
+		"x-amz-algorithm":  PostAlgorithm,
+		"x-amz-credential": fmt.Sprintf("%s/%s/%s/s3/aws4_request", accessKey, date, region),
+		"x-amz-date":       now.Format("20060102T150405Z"),
+	}
+
+	// Every form field but the signature must be matched by a condition
+	policyConditions := []interface{}{map[string]string{"bucket": bucket}}
+	for _, name := range []string{"key", "x-amz-algorithm", "x-amz-credential", "x-amz-date"} {
+		policyConditions = append(policyConditions, map[string]string{name: fields[name]})
+	}
+	if conditions.MaxContentLength > 0 {
+		policyConditions = append(policyConditions, []interface{}{"content-length-range", conditions.MinContentLength, conditions.MaxContentLength})
+	}
+	policy, err := json.Marshal(map[string]interface{}{
+		"expiration": now.Add(expiry).Format("2006-01-02T15:04:05.000Z"),
+		"conditions": policyConditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fields["policy"] = base64.StdEncoding.EncodeToString(policy)
+	fields["x-amz-signature"] = signPolicy(secretKey, date, region, fields["policy"])
+	return &PresignedPost{
+		URL:    strings.TrimSuffix(endpoint, "/") + "/" + bucket,
+		Fields: fields,
+	}, nil
+}
+
+// signPolicy returns the hex signature V4 of a base64 encoded POST policy
+func signPolicy(secretKey, date, region, policy string) string {
+	return hex.EncodeToString(hmacSHA256(signingKey(secretKey, date, region, "s3"), policy))
+}
+
+// signingKey derives the signature V4 key of date (YYYYMMDD), region and service
+func signingKey(secretKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+var spinnerFrames = []rune{'|', '/', '-', '\\'}
+
+// Progress renders a spinner with a running count to stderr
+type Progress struct {
+	mu      sync.Mutex
+	out     io.Writer
+	label   string
+	count   *Counter
+	frame   int
+	enabled bool
+}
+
+// NewProgress gets a new Progress, which no-ops when stderr isn't a TTY
+func NewProgress(label string) *Progress {
+	return NewProgressWithCounter(label, &Counter{})
+}
+
+// NewProgressWithCounter gets a new Progress that counts in counter, so that the
+// caller can read the count of concurrent workers from it
+func NewProgressWithCounter(label string, counter *Counter) *Progress {
+	return &Progress{
+		out:     os.Stderr,
+		label:   label,
+		count:   counter,
+		enabled: isTerminal(os.Stderr),
+	}
+}
+
+// Start renders the initial zero count
+func (p *Progress) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+}
+
+// Increment adds n to the count and advances the spinner, it's safe for concurrent use
+func (p *Progress) Increment(n int) {
+	p.count.Add(int64(n))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frame++
+	p.render()
+}
+
+// Done renders the final count and ends the line
+func (p *Progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.enabled {
+		fmt.Fprintf(p.out, "\r%s: %d done\n", p.label, p.count.Snapshot())
+	}
+}
+
+func (p *Progress) render() {
+	if p.enabled {
+		fmt.Fprintf(p.out, "\r%c %s: %d", spinnerFrames[p.frame%len(spinnerFrames)], p.label, p.count.Snapshot())
+	}
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -15,23 +15,79 @@ package utils
  * permissions and limitations under the License.
  */
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/jacobstr/confer"
 )
 
-// GetS3Client is to get S3 client to ECS server
+// NamespaceHeader selects the ECS namespace a request is served from
+const NamespaceHeader = "x-emc-namespace"
+
+const (
+	// Version of the samples
+	Version = "1.0.0"
+	// DefaultUserAgent is appended to the SDK's User-Agent when s3.user_agent isn't set
+	DefaultUserAgent = "ecs-sample/" + Version
+)
+
+// GetS3Client is to get S3 client to ECS server, the client satisfies S3API.
+// Invalid settings are returned as ConfigError.
 func GetS3Client(config *confer.Config) (*s3.S3, error) {
+	return GetS3ClientFor(config, DefaultEndpoint)
+}
+
+// GetS3ClientFor is GetS3Client for the named endpoint of s3.endpoints, DefaultEndpoint
+// uses the top-level s3 settings. Settings other than endpoint, access_key and
+// secret_key that an endpoint doesn't set are taken from the top-level ones.
+func GetS3ClientFor(config *confer.Config, name string) (*s3.S3, error) {
+	e, err := newEndpointConfig(config, name)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get Config
 	s3Config := &aws.Config{
-		Credentials: credentials.NewStaticCredentials(config.GetString("s3.access_key"), config.GetString("s3.secret_key"), ""),
-		Endpoint:    aws.String(config.GetString("s3.endpoint")),
-		Region:      aws.String(config.GetString("s3.region")),
+		Credentials: credentials.NewStaticCredentials(e.getString("access_key"), e.getString("secret_key"), ""),
+		Endpoint:    aws.String(e.getString("endpoint")),
+		Region:      aws.String(e.getString("region")),
+	}
+
+	// Send unsigned requests, only public buckets/objects can be accessed
+	if e.getBool("anonymous") {
+		log.Println("s3.anonymous is set: request signing is disabled and access_key/secret_key are ignored")
+		s3Config.Credentials = credentials.AnonymousCredentials
+	}
+
+	// Trust an internal CA instead of the system roots
+	if caCertFile := e.getString("ca_cert_file"); len(caCertFile) > 0 {
+		httpClient, err := newCACertClient(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		s3Config.HTTPClient = httpClient
+	}
+
+	// V2 signing needs the bucket in the path, see v2SignHandler
+	signatureVersion := e.getString("signature_version")
+	switch signatureVersion {
+	case "", SignatureV4:
+	case SignatureV2:
+		s3Config.S3ForcePathStyle = aws.Bool(true)
+	default:
+		return nil, &ConfigError{Err: fmt.Errorf("%s must be %s or %s, not [%s]", e.key("signature_version"), SignatureV2, SignatureV4, signatureVersion)}
 	}
 
 	// Set log level
@@ -47,14 +103,149 @@ func GetS3Client(config *confer.Config) (*s3.S3, error) {
 	case "LogDebugWithRequestErrors":
 		logLevel = aws.LogDebugWithRequestErrors
 	}
+	// s3.debug_http dumps every request and response, with credentials redacted
+	if e.getBool("debug_http") {
+		logLevel = aws.LogDebugWithHTTPBody
+	}
 	s3Config.WithLogLevel(logLevel)
 
-	// Create Session
+	// Log to stderr, stdout may carry object content, see ecs get
+	s3Config.WithLogger(sdkLogger(log.New(os.Stderr, "", log.LstdFlags)))
+
+	// Handlers every request of the client, the region detection included, carries
+	settings := &clientSettings{
+		signatureVersion: signatureVersion,
+		namespace:        e.getString("namespace"),
+		userAgent:        e.getString("user_agent"),
+	}
+	if len(settings.userAgent) == 0 {
+		// Identify the samples' traffic in the server's request logs
+		settings.userAgent = DefaultUserAgent
+	}
+
+	// Create S3 Client
+	s3client, err := newS3Client(s3Config, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rebuild the client for the region the demo bucket actually lives in
+	if e.getBool("autodetect_region") {
+		region := bucketRegion(s3client, e.getString("endpoint"), e.bucket())
+		if len(region) > 0 && region != aws.StringValue(s3Config.Region) {
+			s3Config.Region = aws.String(region)
+			if s3client, err = newS3Client(s3Config, settings); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s3client, nil
+}
+
+// clientSettings are the request handlers newS3Client installs
+type clientSettings struct {
+	signatureVersion string
+	// namespace is sent as NamespaceHeader unless it's empty
+	namespace string
+	userAgent string
+}
+
+// useSignatureV2 replaces the V4 signer of s3client with v2SignHandler,
+// the other sign handlers of the SDK are kept
+func useSignatureV2(s3client *s3.S3) {
+	if !s3client.Handlers.Sign.Swap(v4.SignRequestHandler.Name, v2SignHandler()) {
+		s3client.Handlers.Sign.PushBackNamed(v2SignHandler())
+	}
+}
+
+// userAgentHandler appends userAgent to the User-Agent the SDK sends
+func userAgentHandler(userAgent string) request.NamedHandler {
+	return request.NamedHandler{
+		Name: "ecs.UserAgentHandler",
+		Fn:   request.MakeAddToUserAgentFreeFormHandler(userAgent),
+	}
+}
+
+// newCACertClient gets an HTTP client trusting only the PEM certificates in caCertFile
+func newCACertClient(caCertFile string) (*http.Client, error) {
+	pem, err := ioutil.ReadFile(caCertFile)
+	if err != nil {
+		return nil, &ConfigError{Err: fmt.Errorf("failed to read s3.ca_cert_file: %v", err)}
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, &ConfigError{Err: fmt.Errorf("s3.ca_cert_file [%s] contains no valid PEM certificates", caCertFile)}
+	}
+	// Keep the proxy, timeouts and connection limits of the default transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	return &http.Client{Transport: transport}, nil
+}
+
+// newS3Client creates a session and an S3 client from s3Config with the handlers
+// of settings, so that even its first request, e.g. the region detection, is
+// signed and routed the way the endpoint expects
+func newS3Client(s3Config *aws.Config, settings *clientSettings) (*s3.S3, error) {
 	newSession, err := session.NewSession(s3Config)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to create S3 session")
+		return nil, &ConfigError{Err: fmt.Errorf("failed to create S3 session: %v", err)}
 	}
+	s3client := s3.New(newSession)
 
-	// Create S3 Client
-	return s3.New(newSession), nil
+	// Route requests to a non-default ECS namespace
+	if len(settings.namespace) > 0 {
+		s3client.Handlers.Build.PushBackNamed(namespaceHandler(settings.namespace))
+	}
+	if len(settings.userAgent) > 0 {
+		s3client.Handlers.Build.PushBackNamed(userAgentHandler(settings.userAgent))
+	}
+
+	// Sign the legacy way for endpoints that reject signature V4
+	if settings.signatureVersion == SignatureV2 {
+		useSignatureV2(s3client)
+	}
+	return s3client, nil
+}
+
+// bucketRegions caches the detected region per endpoint and bucket
+var bucketRegions = struct {
+	sync.Mutex
+	m map[string]string
+}{m: map[string]string{}}
+
+// bucketRegion returns the region of bucket from GetBucketLocation, or "" if
+// it can't be found out. Endpoints that don't implement the API are skipped
+// quietly; a found region is cached so it's only asked once, a failure isn't.
+func bucketRegion(s3client *s3.S3, endpoint, bucket string) string {
+	if len(bucket) == 0 {
+		return ""
+	}
+	cacheKey := endpoint + "/" + bucket
+
+	bucketRegions.Lock()
+	defer bucketRegions.Unlock()
+	if region, ok := bucketRegions.m[cacheKey]; ok {
+		return region
+	}
+
+	resp, err := s3client.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return ""
+	}
+	region := s3.NormalizeBucketLocation(aws.StringValue(resp.LocationConstraint))
+	bucketRegions.m[cacheKey] = region
+	return region
+}
+
+// namespaceHandler sets NamespaceHeader on every request. Without it ECS serves
+// the bucket from the namespace the access key belongs to; with it the bucket
+// name stays unchanged in the host (bucket.endpoint) or path (endpoint/bucket),
+// which is what lets the same bucket name exist in several namespaces.
+func namespaceHandler(namespace string) request.NamedHandler {
+	return request.NamedHandler{
+		Name: "ecs.NamespaceHandler",
+		Fn: func(r *request.Request) {
+			r.HTTPRequest.Header.Set(NamespaceHeader, namespace)
+		},
+	}
 }
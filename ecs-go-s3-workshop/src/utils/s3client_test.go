@@ -0,0 +1,182 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+type S3ClientSuite struct{}
+
+var _ = Suite(&S3ClientSuite{})
+
+// newTestClient gets an S3 client for a fake endpoint, requests are built but never sent
+func newTestClient(c *C) *s3.S3 {
+	newSession, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials("access", "secret", ""),
+		Endpoint:    aws.String("https://ecs.example.com"),
+		Region:      aws.String("us-east-1"),
+	})
+	c.Assert(err, IsNil)
+	return s3.New(newSession)
+}
+
+func (s *S3ClientSuite) TestNamespaceHandler(c *C) {
+	s3client := newTestClient(c)
+	s3client.Handlers.Build.PushBackNamed(namespaceHandler("ns1"))
+
+	req, _ := s3client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String("workshop-bucket"),
+		Key:    aws.String("key"),
+	})
+	c.Assert(req.Build(), IsNil)
+
+	c.Check(req.HTTPRequest.Header.Get(NamespaceHeader), Equals, "ns1")
+	c.Check(req.HTTPRequest.URL.Host, Equals, "workshop-bucket.ecs.example.com")
+	c.Check(req.HTTPRequest.URL.Path, Equals, "/key")
+}
+
+func (s *S3ClientSuite) TestNoNamespaceHeaderByDefault(c *C) {
+	s3client := newTestClient(c)
+
+	req, _ := s3client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String("workshop-bucket"),
+		Key:    aws.String("key"),
+	})
+	c.Assert(req.Build(), IsNil)
+
+	c.Check(req.HTTPRequest.Header.Get(NamespaceHeader), Equals, "")
+}
+
+func (s *S3ClientSuite) TestUserAgentHandler(c *C) {
+	s3client := newTestClient(c)
+	s3client.Handlers.Build.PushBackNamed(userAgentHandler(DefaultUserAgent))
+
+	req, _ := s3client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String("workshop-bucket"),
+		Key:    aws.String("key"),
+	})
+	c.Assert(req.Build(), IsNil)
+
+	userAgent := req.HTTPRequest.Header.Get("User-Agent")
+	c.Check(strings.HasPrefix(userAgent, "aws-sdk-go/"), Equals, true)
+	c.Check(strings.HasSuffix(userAgent, " ecs-sample/"+Version), Equals, true)
+}
+
+// writeCACert writes a self-signed PEM certificate to a file in dir
+func writeCACert(c *C, dir string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Workshop CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, IsNil)
+
+	path := filepath.Join(dir, "ca.pem")
+	c.Assert(ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644), IsNil)
+	return path
+}
+
+func (s *S3ClientSuite) TestCACertClient(c *C) {
+	httpClient, err := newCACertClient(writeCACert(c, c.MkDir()))
+	c.Assert(err, IsNil)
+	transport := httpClient.Transport.(*http.Transport)
+	c.Check(transport.TLSClientConfig.RootCAs.Subjects(), HasLen, 1)
+
+	// Everything but the TLS config is that of the default transport
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+	c.Check(transport, Not(Equals), defaultTransport)
+	c.Check(transport.Proxy, NotNil)
+	c.Check(transport.TLSHandshakeTimeout, Equals, defaultTransport.TLSHandshakeTimeout)
+	c.Check(transport.IdleConnTimeout, Equals, defaultTransport.IdleConnTimeout)
+	c.Check(transport.MaxIdleConns, Equals, defaultTransport.MaxIdleConns)
+	c.Check(defaultTransport.TLSClientConfig == nil || defaultTransport.TLSClientConfig.RootCAs == nil, Equals, true)
+}
+
+func (s *S3ClientSuite) TestCACertClientInvalid(c *C) {
+	path := filepath.Join(c.MkDir(), "ca.pem")
+	c.Assert(ioutil.WriteFile(path, []byte("not a certificate"), 0644), IsNil)
+	_, err := newCACertClient(path)
+	c.Check(err, ErrorMatches, `config: s3.ca_cert_file \[.*\] contains no valid PEM certificates`)
+
+	_, err = newCACertClient(filepath.Join(c.MkDir(), "missing.pem"))
+	c.Check(err, ErrorMatches, "config: failed to read s3.ca_cert_file: .*")
+}
+
+func (s *S3ClientSuite) TestSignerPerVersion(c *C) {
+	s3client := newTestClient(c)
+	req, _ := s3client.GetObjectRequest(&s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	c.Assert(req.Sign(), IsNil)
+	c.Check(strings.HasPrefix(req.HTTPRequest.Header.Get("Authorization"), "AWS4-HMAC-SHA256 "), Equals, true)
+
+	s3client = newTestClient(c)
+	useSignatureV2(s3client)
+	req, _ = s3client.GetObjectRequest(&s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	c.Assert(req.Sign(), IsNil)
+	c.Check(strings.HasPrefix(req.HTTPRequest.Header.Get("Authorization"), "AWS access:"), Equals, true)
+}
+
+func (s *S3ClientSuite) TestNewS3ClientSignsV2FromTheStart(c *C) {
+	// The region detection is the first request of a new client, it must already be signed V2
+	s3client, err := newS3Client(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("access", "secret", ""),
+		Endpoint:         aws.String("https://ecs.example.com"),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	}, &clientSettings{signatureVersion: SignatureV2})
+	c.Assert(err, IsNil)
+	req, _ := s3client.GetBucketLocationRequest(&s3.GetBucketLocationInput{Bucket: aws.String("bucket")})
+	c.Assert(req.Sign(), IsNil)
+	c.Check(strings.HasPrefix(req.HTTPRequest.Header.Get("Authorization"), "AWS access:"), Equals, true)
+}
+
+func (s *S3ClientSuite) TestNewS3ClientSetsNamespaceFromTheStart(c *C) {
+	// The region detection must already be routed to the configured namespace
+	s3client, err := newS3Client(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("access", "secret", ""),
+		Endpoint:         aws.String("https://ecs.example.com"),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	}, &clientSettings{namespace: "ns1", userAgent: DefaultUserAgent})
+	c.Assert(err, IsNil)
+	req, _ := s3client.GetBucketLocationRequest(&s3.GetBucketLocationInput{Bucket: aws.String("bucket")})
+	c.Assert(req.Build(), IsNil)
+	c.Check(req.HTTPRequest.Header.Get(NamespaceHeader), Equals, "ns1")
+	c.Check(strings.Contains(req.HTTPRequest.Header.Get("User-Agent"), DefaultUserAgent), Equals, true)
+}
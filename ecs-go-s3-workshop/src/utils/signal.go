@@ -0,0 +1,81 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// ExitInterrupted is the exit status of a sample that stopped early on SIGINT or SIGTERM
+const ExitInterrupted = 130
+
+// SetupSignalContext returns a context that is canceled on the first SIGINT or SIGTERM,
+// long-running samples then stop starting new work and print what they completed.
+// A second signal exits immediately. stop restores the default handling, e.g. before
+// prompting for input.
+func SetupSignalContext() (ctx context.Context, stop func()) {
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	ctx, cancel := signalContext(sigCh, os.Exit)
+	return ctx, func() {
+		// No more signals are delivered to sigCh once stopped, any still
+		// buffered are ignored by signalContext once cancel returns
+		signal.Stop(sigCh)
+		cancel()
+	}
+}
+
+// signalContext cancels the returned context on the first signal received on sigCh and calls exit on
+// the second, until cancel is called
+func signalContext(sigCh <-chan os.Signal, exit func(code int)) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		for received := 0; ; received++ {
+			var sig os.Signal
+			select {
+			case sig = <-sigCh:
+			case <-stopped:
+				return
+			}
+			// select picks at random when both are ready, a signal still
+			// buffered after cancel must not be handled
+			select {
+			case <-stopped:
+				return
+			default:
+			}
+			if received > 0 {
+				exit(ExitInterrupted)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "received %v, waiting for in-flight operations (again to exit now)\n", sig)
+			cancel()
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			close(stopped)
+			cancel()
+		})
+	}
+}
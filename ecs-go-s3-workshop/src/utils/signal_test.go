@@ -0,0 +1,69 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SignalSuite struct{}
+
+var _ = Suite(&SignalSuite{})
+
+func (s *SignalSuite) TestSignalContext(c *C) {
+	sigCh := make(chan os.Signal)
+	exitCh := make(chan int, 1)
+	ctx, cancel := signalContext(sigCh, func(code int) { exitCh <- code })
+	defer cancel()
+	c.Check(ctx.Err(), IsNil)
+
+	// The first signal cancels the context
+	sigCh <- syscall.SIGINT
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		c.Fatal("context not canceled on SIGINT")
+	}
+	c.Check(exitCh, HasLen, 0)
+
+	// The second one exits
+	sigCh <- syscall.SIGTERM
+	select {
+	case code := <-exitCh:
+		c.Check(code, Equals, ExitInterrupted)
+	case <-time.After(time.Second):
+		c.Fatal("no exit on second signal")
+	}
+}
+
+func (s *SignalSuite) TestSignalContextCanceled(c *C) {
+	sigCh := make(chan os.Signal, 2)
+	exitCh := make(chan int, 1)
+	ctx, cancel := signalContext(sigCh, func(code int) { exitCh <- code })
+	cancel()
+	cancel()
+	c.Check(ctx.Err(), NotNil)
+
+	// Signals are no longer handled
+	sigCh <- syscall.SIGINT
+	sigCh <- syscall.SIGINT
+	time.Sleep(10 * time.Millisecond)
+	c.Check(exitCh, HasLen, 0)
+}
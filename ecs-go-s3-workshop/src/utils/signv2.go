@@ -0,0 +1,161 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// Values of s3.signature_version
+const (
+	SignatureV2 = "v2"
+	SignatureV4 = "v4"
+)
+
+// subResources are the query parameters that are part of the V2 canonical resource
+var subResources = map[string]bool{
+	"acl": true, "cors": true, "delete": true, "legal-hold": true, "lifecycle": true,
+	"location": true, "logging": true, "notification": true, "object-lock": true,
+	"partNumber": true, "policy": true, "replication": true, "requestPayment": true,
+	"response-cache-control": true, "response-content-disposition": true,
+	"response-content-encoding": true, "response-content-language": true,
+	"response-content-type": true, "response-expires": true, "restore": true,
+	"retention": true, "select": true, "select-type": true, "tagging": true,
+	"torrent": true, "uploadId": true, "uploads": true, "versionId": true,
+	"versioning": true, "versions": true, "website": true,
+}
+
+// v2SignHandler signs requests with the legacy S3 signature version 2. It only
+// works with path-style URLs, where the bucket is the first path segment.
+func v2SignHandler() request.NamedHandler {
+	return request.NamedHandler{
+		Name: "ecs.V2SignHandler",
+		Fn: func(r *request.Request) {
+			if r.Config.Credentials == credentials.AnonymousCredentials {
+				return
+			}
+			creds, err := r.Config.Credentials.Get()
+			if err != nil {
+				r.Error = err
+				return
+			}
+			signV2(r.HTTPRequest, creds, time.Now().UTC(), r.ExpireTime)
+		},
+	}
+}
+
+// signV2 signs req in its Authorization header, or in its query if it's presigned for expire
+func signV2(req *http.Request, creds credentials.Value, now time.Time, expire time.Duration) {
+	if len(creds.SessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	if expire > 0 {
+		query := req.URL.Query()
+		query.Del("Signature")
+		expires := strconv.FormatInt(now.Add(expire).Unix(), 10)
+		signature := hmacSHA1(creds.SecretAccessKey, stringToSignV2(req, expires))
+		query.Set("AWSAccessKeyId", creds.AccessKeyID)
+		query.Set("Expires", expires)
+		query.Set("Signature", signature)
+		req.URL.RawQuery = query.Encode()
+		return
+	}
+
+	req.Header.Set("Date", now.Format(http.TimeFormat))
+	signature := hmacSHA1(creds.SecretAccessKey, stringToSignV2(req, req.Header.Get("Date")))
+	req.Header.Set("Authorization", "AWS "+creds.AccessKeyID+":"+signature)
+}
+
+// stringToSignV2 returns the V2 string to sign of req, date is its Date header
+// or, for a presigned request, the Expires timestamp
+func stringToSignV2(req *http.Request, date string) string {
+	// With an x-amz-date header, it's signed instead of Date
+	if len(req.Header.Get("X-Amz-Date")) > 0 {
+		date = ""
+	}
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		date,
+	}, "\n") + "\n" + canonicalAmzHeaders(req.Header) + canonicalResource(req.URL)
+}
+
+// canonicalAmzHeaders returns the sorted, lowercased x-amz-* headers, one per line.
+// ECS signs its own x-emc-* headers, such as NamespaceHeader, the same way.
+func canonicalAmzHeaders(header http.Header) string {
+	var names []string
+	for name := range header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") || strings.HasPrefix(lower, "x-emc-") {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+
+	var buf []string
+	for _, name := range names {
+		var values []string
+		for _, value := range header[http.CanonicalHeaderKey(name)] {
+			values = append(values, strings.TrimSpace(value))
+		}
+		buf = append(buf, name+":"+strings.Join(values, ",")+"\n")
+	}
+	return strings.Join(buf, "")
+}
+
+// canonicalResource returns the escaped path of u followed by its sorted sub-resources
+func canonicalResource(u *url.URL) string {
+	resource := u.EscapedPath()
+	if len(resource) == 0 {
+		resource = "/"
+	}
+
+	query := u.Query()
+	var params []string
+	for name := range query {
+		if subResources[name] {
+			params = append(params, name)
+		}
+	}
+	if len(params) == 0 {
+		return resource
+	}
+	sort.Strings(params)
+	for i, name := range params {
+		if value := query.Get(name); len(value) > 0 {
+			params[i] = name + "=" + value
+		}
+	}
+	return resource + "?" + strings.Join(params, "&")
+}
+
+func hmacSHA1(key, data string) string {
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,82 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// BucketStats are aggregates over the objects of a bucket or prefix
+type BucketStats struct {
+	ObjectCount  int64  `json:"objectCount"`
+	TotalBytes   int64  `json:"totalBytes"`
+	LargestKey   string `json:"largestKey,omitempty"`
+	LargestBytes int64  `json:"largestBytes"`
+}
+
+// Add counts an object of size bytes
+func (s *BucketStats) Add(key string, size int64) {
+	s.ObjectCount++
+	s.TotalBytes += size
+	if s.ObjectCount == 1 || size > s.LargestBytes {
+		s.LargestKey = key
+		s.LargestBytes = size
+	}
+}
+
+// String formats the stats for the list summary
+func (s *BucketStats) String() string {
+	if s.ObjectCount == 0 {
+		return "0 objects"
+	}
+	return fmt.Sprintf("%d objects, %d bytes, largest [%s] with %d bytes", s.ObjectCount, s.TotalBytes, s.LargestKey, s.LargestBytes)
+}
+
+// ComputeBucketStats pages through all objects under prefix and aggregates them
+func ComputeBucketStats(s3client S3API, bucket, prefix string) (*BucketStats, error) {
+	return ComputeBucketStatsWithContext(context.Background(), s3client, bucket, prefix, nil)
+}
+
+// ComputeBucketStatsWithContext is ComputeBucketStats that also passes each object
+// to fn unless it's nil, so that a listing needs only one pass. It stops before the
+// next page once ctx is done; on errors and ctx.Err() the stats so far are returned.
+func ComputeBucketStatsWithContext(ctx context.Context, s3client S3API, bucket, prefix string, fn func(obj *s3.Object)) (*BucketStats, error) {
+	params := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+	}
+	if len(prefix) > 0 {
+		params.SetPrefix(prefix)
+	}
+
+	stats := &BucketStats{}
+	err := s3client.ListObjectsV2Pages(params, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			stats.Add(aws.StringValue(obj.Key), aws.Int64Value(obj.Size))
+			if fn != nil {
+				fn(obj)
+			}
+		}
+		return ctx.Err() == nil
+	})
+	if err != nil {
+		return stats, ClassifyError(err, bucket, "")
+	}
+	return stats, ctx.Err()
+}
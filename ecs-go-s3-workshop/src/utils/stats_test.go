@@ -0,0 +1,107 @@
+package utils_test
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"utils"
+	"utils/fake"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	. "gopkg.in/check.v1"
+)
+
+type StatsSuite struct {
+	s3client *fake.S3
+}
+
+var _ = Suite(&StatsSuite{})
+
+func (s *StatsSuite) SetUpTest(c *C) {
+	s.s3client = fake.NewS3("bucket")
+}
+
+func (s *StatsSuite) put(c *C, key string, size int) {
+	_, err := s.s3client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(strings.Repeat("x", size)),
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *StatsSuite) TestComputeBucketStatsPages(c *C) {
+	// More objects than fit in one listing page
+	for i := 0; i < fake.MaxKeys+5; i++ {
+		s.put(c, fmt.Sprintf("logs/%04d", i), i%10)
+	}
+	s.put(c, "logs/big", 100)
+	s.put(c, "other/bigger", 200)
+
+	stats, err := utils.ComputeBucketStats(s.s3client, "bucket", "logs/")
+	c.Assert(err, IsNil)
+	c.Check(*stats, DeepEquals, utils.BucketStats{
+		ObjectCount:  fake.MaxKeys + 6,
+		TotalBytes:   4500 + 10 + 100,
+		LargestKey:   "logs/big",
+		LargestBytes: 100,
+	})
+}
+
+func (s *StatsSuite) TestComputeBucketStatsEmpty(c *C) {
+	stats, err := utils.ComputeBucketStats(s.s3client, "bucket", "")
+	c.Assert(err, IsNil)
+	c.Check(*stats, DeepEquals, utils.BucketStats{})
+	c.Check(stats.String(), Equals, "0 objects")
+}
+
+func (s *StatsSuite) TestComputeBucketStatsEmptyObjects(c *C) {
+	s.put(c, "a", 0)
+	s.put(c, "b", 0)
+
+	stats, err := utils.ComputeBucketStats(s.s3client, "bucket", "")
+	c.Assert(err, IsNil)
+	c.Check(stats.String(), Equals, "2 objects, 0 bytes, largest [a] with 0 bytes")
+}
+
+func (s *StatsSuite) TestComputeBucketStatsNoSuchBucket(c *C) {
+	_, err := utils.ComputeBucketStats(s.s3client, "missing", "")
+	c.Check(err, FitsTypeOf, &utils.NotFoundError{})
+}
+
+func (s *StatsSuite) TestComputeBucketStatsWithContext(c *C) {
+	for i := 0; i < fake.MaxKeys+5; i++ {
+		s.put(c, fmt.Sprintf("logs/%04d", i), 1)
+	}
+
+	var keys []string
+	stats, err := utils.ComputeBucketStatsWithContext(context.Background(), s.s3client, "bucket", "", func(obj *s3.Object) {
+		keys = append(keys, aws.StringValue(obj.Key))
+	})
+	c.Assert(err, IsNil)
+	c.Check(keys, HasLen, fake.MaxKeys+5)
+	c.Check(stats.ObjectCount, Equals, int64(fake.MaxKeys+5))
+
+	// A done context stops after the first page, the stats so far are returned
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stats, err = utils.ComputeBucketStatsWithContext(ctx, s.s3client, "bucket", "", nil)
+	c.Check(err, Equals, context.Canceled)
+	c.Check(stats.ObjectCount, Equals, int64(fake.MaxKeys))
+}
@@ -0,0 +1,151 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Throttle tuning: how fast the delay grows and how many successes ramp concurrency back up
+const (
+	ThrottleDelayStep   = 200 * time.Millisecond
+	ThrottleMaxDelay    = 5 * time.Second
+	ThrottleRampUpAfter = 10
+)
+
+// Throttle limits the number of concurrent requests, backing off when the server
+// answers SlowDown and ramping back up once requests succeed again
+type Throttle struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	initial   int
+	min       int
+	max       int
+	active    int
+	delay     time.Duration
+	successes int
+	throttled bool
+}
+
+// NewThrottle gets a Throttle allowing initial concurrent requests, adapting between min and max
+func NewThrottle(initial, min, max int) *Throttle {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	if initial < min {
+		initial = min
+	} else if initial > max {
+		initial = max
+	}
+	t := &Throttle{limit: initial, initial: initial, min: min, max: max}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// Acquire waits for a free slot and the current delay, it must be paired with Release
+func (t *Throttle) Acquire() {
+	t.mu.Lock()
+	for t.active >= t.limit {
+		t.cond.Wait()
+	}
+	t.active++
+	delay := t.delay
+	t.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+// Release frees the slot taken by Acquire and adapts to err, the outcome of the request
+func (t *Throttle) Release(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	defer t.cond.Broadcast()
+
+	if IsSlowDown(err) {
+		t.backoff()
+	} else if err == nil {
+		t.rampUp()
+	}
+}
+
+// Limit returns the current number of allowed concurrent requests
+func (t *Throttle) Limit() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit
+}
+
+// Delay returns the current delay before each request
+func (t *Throttle) Delay() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.delay
+}
+
+// backoff halves the concurrency and doubles the delay, the caller must hold t.mu.
+// The SDK already retried the request, so a SlowDown here means it kept happening.
+func (t *Throttle) backoff() {
+	t.successes = 0
+	t.throttled = true
+	t.limit /= 2
+	if t.limit < t.min {
+		t.limit = t.min
+	}
+	t.delay *= 2
+	if t.delay < ThrottleDelayStep {
+		t.delay = ThrottleDelayStep
+	} else if t.delay > ThrottleMaxDelay {
+		t.delay = ThrottleMaxDelay
+	}
+	log.Printf("server asked to slow down: throttling to %d concurrent requests with %v delay", t.limit, t.delay)
+}
+
+// rampUp first removes the delay, then adds concurrency back every
+// ThrottleRampUpAfter successes, the caller must hold t.mu
+func (t *Throttle) rampUp() {
+	t.successes++
+	if t.successes < ThrottleRampUpAfter {
+		return
+	}
+	t.successes = 0
+
+	switch {
+	case t.delay > ThrottleDelayStep:
+		t.delay /= 2
+	case t.delay > 0:
+		t.delay = 0
+	case t.limit < t.max:
+		t.limit++
+	}
+
+	if t.throttled && t.delay == 0 && t.limit >= t.initial {
+		t.throttled = false
+		log.Printf("throttling lifted: back to %d concurrent requests", t.limit)
+	}
+}
+
+// IsSlowDown reports whether err is a 503 SlowDown asking the client to reduce its request rate
+func IsSlowDown(err error) bool {
+	return awsStatusCode(err) == http.StatusServiceUnavailable || IsAWSErrCode(err, ErrCodeSlowDown)
+}
@@ -0,0 +1,105 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	. "gopkg.in/check.v1"
+)
+
+type ThrottleSuite struct{}
+
+var _ = Suite(&ThrottleSuite{})
+
+var slowDown = awserr.NewRequestFailure(awserr.New(ErrCodeSlowDown, "Please reduce your request rate.", nil), 503, "req-1")
+
+func (s *ThrottleSuite) TestNewThrottleBounds(c *C) {
+	c.Check(NewThrottle(8, 1, 4).Limit(), Equals, 4)
+	c.Check(NewThrottle(0, 2, 4).Limit(), Equals, 2)
+	c.Check(NewThrottle(3, 0, 0).Limit(), Equals, 1)
+}
+
+func (s *ThrottleSuite) TestBackoff(c *C) {
+	t := NewThrottle(8, 2, 8)
+	t.Release(slowDown)
+	c.Check(t.Limit(), Equals, 4)
+	c.Check(t.Delay(), Equals, ThrottleDelayStep)
+
+	t.Release(slowDown)
+	t.Release(slowDown)
+	c.Check(t.Limit(), Equals, 2)
+	c.Check(t.Delay(), Equals, 4*ThrottleDelayStep)
+
+	// Other errors don't affect the throttle
+	t.Release(errors.New("boom"))
+	c.Check(t.Limit(), Equals, 2)
+	c.Check(t.Delay(), Equals, 4*ThrottleDelayStep)
+}
+
+func (s *ThrottleSuite) TestRampUp(c *C) {
+	t := NewThrottle(4, 1, 4)
+	t.Release(slowDown)
+	t.Release(slowDown)
+	c.Check(t.Limit(), Equals, 1)
+	c.Check(t.Delay(), Equals, 2*ThrottleDelayStep)
+
+	// The delay goes first, then one more request per ThrottleRampUpAfter successes
+	for i := 0; i < 5*ThrottleRampUpAfter; i++ {
+		t.Release(nil)
+	}
+	c.Check(t.Delay(), Equals, time.Duration(0))
+	c.Check(t.Limit(), Equals, 4)
+
+	for i := 0; i < ThrottleRampUpAfter; i++ {
+		t.Release(nil)
+	}
+	c.Check(t.Limit(), Equals, 4)
+}
+
+func (s *ThrottleSuite) TestAcquireWaitsForSlot(c *C) {
+	t := NewThrottle(1, 1, 1)
+	t.Acquire()
+
+	acquired := make(chan bool)
+	go func() {
+		t.Acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		c.Fatal("acquired a slot beyond the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	t.Release(nil)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		c.Fatal("slot wasn't freed by Release")
+	}
+	t.Release(nil)
+}
+
+func (s *ThrottleSuite) TestIsSlowDown(c *C) {
+	c.Check(IsSlowDown(slowDown), Equals, true)
+	c.Check(IsSlowDown(awserr.New(ErrCodeSlowDown, "Please reduce your request rate.", nil)), Equals, true)
+	c.Check(IsSlowDown(awserr.New(ErrCodeAccessDenied, "Access Denied", nil)), Equals, false)
+	c.Check(IsSlowDown(nil), Equals, false)
+}
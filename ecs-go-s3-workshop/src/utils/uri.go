@@ -0,0 +1,99 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// S3URIScheme prefixes S3 URIs such as s3://bucket/key
+const S3URIScheme = "s3://"
+
+// bucketNameRE are 3 to 63 lowercase letters, digits, dots and hyphens that start and end with a letter or digit
+var bucketNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// IsS3URI reports whether s starts with s3://
+func IsS3URI(s string) bool {
+	return strings.HasPrefix(s, S3URIScheme)
+}
+
+// ParseS3URI splits s3://bucket/key, or bucket/key without the scheme, into its
+// bucket and key. The key may be empty, as in s3://bucket or s3://bucket/.
+func ParseS3URI(uri string) (bucket, key string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(uri, S3URIScheme), "/", 2)
+	bucket = parts[0]
+	if len(parts) > 1 {
+		key = parts[1]
+	}
+	if err := ValidateBucketName(bucket); err != nil {
+		return "", "", fmt.Errorf("invalid S3 URI [%s]: %v", uri, err)
+	}
+	return bucket, key, nil
+}
+
+// ParseS3ObjectURI is ParseS3URI for URIs that must name an object, an empty key is an error
+func ParseS3ObjectURI(uri string) (bucket, key string, err error) {
+	bucket, key, err = ParseS3URI(uri)
+	if err == nil && len(key) == 0 {
+		err = fmt.Errorf("invalid S3 URI [%s]: no object key", uri)
+	}
+	return bucket, key, err
+}
+
+// ObjectURL returns the unsigned URL of bucket/key on endpoint, virtual-hosted
+// style (https://bucket.host/key) unless pathStyle is set. Like the SDK it falls
+// back to path style (https://host/bucket/key) for bucket names that can't be a
+// host name, and for names with dots over https where they'd break the certificate.
+func ObjectURL(endpoint string, pathStyle bool, bucket, key string) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if len(u.Host) == 0 {
+		return "", fmt.Errorf("endpoint [%s] has no host", endpoint)
+	}
+
+	hostCompatible := ValidateBucketName(bucket) == nil && !(u.Scheme == "https" && strings.Contains(bucket, "."))
+	if pathStyle || !hostCompatible {
+		u.Path = "/" + bucket + "/" + key
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	return u.String(), nil
+}
+
+// ValidateBucketName checks name against the S3 bucket naming rules
+func ValidateBucketName(name string) error {
+	switch {
+	case len(name) == 0:
+		return fmt.Errorf("empty bucket name")
+	case !bucketNameRE.MatchString(name):
+		return fmt.Errorf("bucket name [%s] must be 3-63 lowercase letters, digits, dots or hyphens, starting and ending with a letter or digit", name)
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("bucket name [%s] must not contain consecutive dots", name)
+	case net.ParseIP(name) != nil:
+		return fmt.Errorf("bucket name [%s] must not be an IP address", name)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package utils
+
+/*
+ * Copyright 2016 EMC Corporation. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0.txt
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type URISuite struct{}
+
+var _ = Suite(&URISuite{})
+
+func (s *URISuite) TestParseS3URI(c *C) {
+	for uri, expected := range map[string][2]string{
+		"s3://bucket/key":             {"bucket", "key"},
+		"s3://bucket/dir/sub/key.txt": {"bucket", "dir/sub/key.txt"},
+		"s3://my.bucket-1/":           {"my.bucket-1", ""},
+		"s3://bucket":                 {"bucket", ""},
+		"bucket/dir/key":              {"bucket", "dir/key"},
+		"s3://bucket/dir/":            {"bucket", "dir/"},
+	} {
+		bucket, key, err := ParseS3URI(uri)
+		c.Assert(err, IsNil, Commentf("%s", uri))
+		c.Check([2]string{bucket, key}, Equals, expected, Commentf("%s", uri))
+	}
+}
+
+func (s *URISuite) TestParseS3URIInvalidBucket(c *C) {
+	for _, uri := range []string{"s3://", "s3:///key", "s3://Bucket/key", "s3://ab/key", "s3://-bucket/key",
+		"s3://bucket-/key", "s3://my..bucket/key", "s3://192.168.1.1/key", "s3://under_score/key"} {
+		_, _, err := ParseS3URI(uri)
+		c.Check(err, ErrorMatches, "invalid S3 URI .*", Commentf("%s", uri))
+	}
+}
+
+func (s *URISuite) TestParseS3ObjectURI(c *C) {
+	bucket, key, err := ParseS3ObjectURI("s3://bucket/key")
+	c.Assert(err, IsNil)
+	c.Check(bucket, Equals, "bucket")
+	c.Check(key, Equals, "key")
+
+	for _, uri := range []string{"s3://bucket", "s3://bucket/"} {
+		_, _, err = ParseS3ObjectURI(uri)
+		c.Check(err, ErrorMatches, "invalid S3 URI \\[.*\\]: no object key", Commentf("%s", uri))
+	}
+}
+
+func (s *URISuite) TestIsS3URI(c *C) {
+	c.Check(IsS3URI("s3://bucket/key"), Equals, true)
+	c.Check(IsS3URI("bucket/key"), Equals, false)
+	c.Check(IsS3URI("S3://bucket/key"), Equals, false)
+}
+
+func (s *URISuite) TestObjectURL(c *C) {
+	for _, t := range []struct {
+		endpoint  string
+		pathStyle bool
+		bucket    string
+		expected  string
+	}{
+		{"https://object.ecstestdrive.com", false, "bucket", "https://bucket.object.ecstestdrive.com/dir/my%20key.txt"},
+		{"https://object.ecstestdrive.com", true, "bucket", "https://object.ecstestdrive.com/bucket/dir/my%20key.txt"},
+		{"http://10.1.1.1:9020", true, "bucket", "http://10.1.1.1:9020/bucket/dir/my%20key.txt"},
+		{"ecs.example.com:9021", false, "bucket", "https://bucket.ecs.example.com:9021/dir/my%20key.txt"},
+		{"https://ecs.example.com", false, "my.bucket", "https://ecs.example.com/my.bucket/dir/my%20key.txt"},
+		{"http://ecs.example.com", false, "my.bucket", "http://my.bucket.ecs.example.com/dir/my%20key.txt"},
+		{"https://ecs.example.com", false, "Legacy_Bucket", "https://ecs.example.com/Legacy_Bucket/dir/my%20key.txt"},
+	} {
+		u, err := ObjectURL(t.endpoint, t.pathStyle, t.bucket, "dir/my key.txt")
+		c.Assert(err, IsNil)
+		c.Check(u, Equals, t.expected, Commentf("%s %t %s", t.endpoint, t.pathStyle, t.bucket))
+	}
+
+	_, err := ObjectURL("https://", false, "bucket", "key")
+	c.Check(err, ErrorMatches, "endpoint .* has no host")
+}